@@ -0,0 +1,21 @@
+package main
+
+import (
+	"flag"
+	"log/slog"
+	"music-library/internal/fakeapi"
+	"net/http"
+)
+
+// Runs the fake song-info provider for local development.
+// Point EXTERNAL_API_URL at http://localhost:8081/info to use it.
+func main() {
+	addr := flag.String("addr", ":8081", "address to listen on")
+	flag.Parse()
+
+	slog.Info("Starting fake song-info provider", "addr", *addr)
+	if err := http.ListenAndServe(*addr, fakeapi.NewServer()); err != nil {
+		slog.Error("Fake provider failed to start", "error", err)
+		return
+	}
+}
@@ -1,15 +1,23 @@
 package main
 
 import (
+	"context"
 	"log/slog"
+	"music-library/internal/auth"
 	"music-library/internal/config"
 	"music-library/internal/db"
 	"music-library/internal/handlers"
+	"music-library/internal/metrics"
 	"music-library/internal/migrations"
+	"music-library/internal/ratelimit"
 	"music-library/internal/repository"
+	"music-library/internal/resilience"
 	"music-library/internal/router"
 	"music-library/internal/services"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 
 	_ "github.com/golang-migrate/migrate/v4/database/postgres"
 	_ "github.com/golang-migrate/migrate/v4/source/file"
@@ -21,37 +29,197 @@ import (
 // @host localhost:8080
 // @BasePath /api/v1
 func main() {
+	if err := run(); err != nil {
+		os.Exit(1)
+	}
+}
+
+// run starts the service and blocks until it is shut down. Errors are logged before they are returned.
+func run() error {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
 	cfg, err := config.LoadConfig()
 	if err != nil {
 		slog.Error("Configuration loading error", "error", err)
-		return
+		return err
 	}
 	slog.Info("Configuration loaded successfully")
 
 	database, err := db.InitDB(cfg.DBHost, cfg.DBPort, cfg.DBUser, cfg.DBPassword, cfg.DBName)
 	if err != nil {
 		slog.Error("Database connection failed", "host", cfg.DBHost, "port", cfg.DBPort, "error", err)
-		return
+		return err
 	}
-	defer database.Close()
+	defer func() {
+		database.Close()
+		slog.Info("Database connection closed")
+	}()
 	slog.Info("Database connection successfully")
+	metrics.RegisterDB(database, cfg.DBName)
 
-	err = migrations.ApplyMigrations(cfg.DBHost, cfg.DBPort, cfg.DBUser, cfg.DBPassword, cfg.DBName)
+	schemaVersion, err := migrations.ApplyMigrations(cfg.DBHost, cfg.DBPort, cfg.DBUser, cfg.DBPassword, cfg.DBName)
 	if err != nil {
 		slog.Error("Migrations failed", "error", err)
-		return
+		return err
+	}
+	slog.Info("Migrations executed successfully", "version", schemaVersion)
+
+	enricher, err := services.NewHTTPEnricher(services.HTTPEnricherConfig{
+		BaseURL:        cfg.ExternalAPI,
+		Timeout:        cfg.ExternalAPITimeout,
+		ConnectTimeout: cfg.ExternalAPIConnectTimeout,
+		Retry: resilience.RetryPolicy{
+			MaxAttempts: cfg.ExternalAPIMaxAttempts,
+			BaseDelay:   cfg.ExternalAPIRetryBaseDelay,
+			MaxDelay:    cfg.ExternalAPIRetryMaxDelay,
+		},
+		Breaker: resilience.BreakerConfig{
+			FailureThreshold: cfg.BreakerFailureThreshold,
+			OpenTimeout:      cfg.BreakerOpenTimeout,
+			HalfOpenMaxCalls: cfg.BreakerHalfOpenMaxRequests,
+		},
+	})
+	if err != nil {
+		slog.Error("Enrichment provider setup failed", "error", err)
+		return err
 	}
-	slog.Info("Migrations executed successfully")
 
 	repo := repository.NewSongRepository(database)
-	service := services.NewSongService(repo)
-	handler := handlers.NewSongHandler(service)
+	service := services.NewSongService(repo, enricher, services.SearchConfig{
+		Languages:           cfg.SearchLanguages,
+		DefaultLanguage:     cfg.SearchDefaultLanguage,
+		SimilarityThreshold: cfg.SearchSimilarityThreshold,
+	})
+	jobRepo := repository.NewJobRepository(database)
+	jobService := services.NewJobService(jobRepo, service, services.JobConfig{
+		Workers:      cfg.JobWorkers,
+		PollInterval: cfg.JobPollInterval,
+		Lease:        cfg.JobLease,
+		MaxAttempts:  cfg.JobMaxAttempts,
+		Retry: resilience.RetryPolicy{
+			BaseDelay: cfg.JobRetryBaseDelay,
+			MaxDelay:  cfg.JobRetryMaxDelay,
+		},
+	})
+	jobService.Start(ctx)
+
+	purgeService := services.NewPurgeService(repo, services.PurgeConfig{
+		Retention: cfg.TrashRetention,
+		Interval:  cfg.TrashPurgeInterval,
+	})
+	purgeService.Start(ctx)
+
+	lyricsBackfill := services.NewLyricsBackfillService(repo)
+	lyricsBackfill.Start(ctx)
+
+	var prober services.Prober
+	if cfg.ReadyCheckEnricher {
+		prober = enricher
+	}
+	healthService := services.NewHealthService(repository.NewHealthRepository(database), prober, services.HealthConfig{
+		SchemaVersion: schemaVersion,
+		CheckTimeout:  cfg.ReadyCheckTimeout,
+	})
+
+	importService := services.NewImportService(service, services.ImportConfig{
+		Concurrency: cfg.ImportConcurrency,
+		MaxRows:     cfg.ImportMaxRows,
+	})
+
+	artistService := services.NewArtistService(repository.NewArtistRepository(database), service)
+	albumService := services.NewAlbumService(repository.NewAlbumRepository(database), service)
+	playlistService := services.NewPlaylistService(repository.NewPlaylistRepository(database), service)
+
+	var tokens *auth.TokenVerifier
+	if cfg.AuthJWTSecret != "" {
+		tokens = auth.NewTokenVerifier(auth.TokenConfig{
+			Secret:   []byte(cfg.AuthJWTSecret),
+			Issuer:   cfg.AuthJWTIssuer,
+			Audience: cfg.AuthJWTAudience,
+			Leeway:   cfg.AuthJWTLeeway,
+		})
+	}
+	authService := services.NewAuthService(repository.NewAPIKeyRepository(database), tokens)
+	if cfg.AuthBootstrapKey != "" {
+		if err := authService.EnsureBootstrapKey(ctx, cfg.AuthBootstrapKey); err != nil {
+			slog.Error("Bootstrap key registration failed", "error", err)
+			return err
+		}
+	}
+
+	var rateLimits *handlers.RateLimiter
+	if cfg.RateLimitEnabled {
+		rateLimits = handlers.NewRateLimiter(
+			ratelimit.NewLimiter(cfg.RateLimitRead),
+			ratelimit.NewLimiter(cfg.RateLimitWrite),
+			cfg.RateLimitTrustProxy,
+		)
+	}
+
+	r := router.NewRouter(router.Handlers{
+		Songs:     handlers.NewSongHandler(service, jobService),
+		Jobs:      handlers.NewJobHandler(jobService),
+		Imports:   handlers.NewImportHandler(importService, rateLimits),
+		Health:    handlers.NewHealthHandler(healthService, enricher),
+		Artists:   handlers.NewArtistHandler(artistService),
+		Albums:    handlers.NewAlbumHandler(albumService),
+		Playlists: handlers.NewPlaylistHandler(playlistService),
+		Auth:      handlers.NewAuthHandler(authService),
 
-	r := router.NewRouter(handler)
+		RateLimits: rateLimits,
+	}, router.Timeouts{
+		Read:         cfg.ReadRequestTimeout,
+		Write:        cfg.WriteRequestTimeout,
+		Import:       cfg.ImportRequestTimeout,
+		Export:       cfg.ExportRequestTimeout,
+		Autocomplete: cfg.AutocompleteRequestTimeout,
+	})
 
-	slog.Info("Starting server", "port", cfg.APIPort)
-	if err := http.ListenAndServe(":"+cfg.APIPort, r); err != nil {
-		slog.Error("Server failed to start", "error", err)
-		return
+	server := &http.Server{
+		Addr:              ":" + cfg.APIPort,
+		Handler:           r,
+		ReadTimeout:       cfg.ServerReadTimeout,
+		ReadHeaderTimeout: cfg.ServerReadHeaderTimeout,
+		WriteTimeout:      cfg.ServerWriteTimeout,
+		IdleTimeout:       cfg.ServerIdleTimeout,
 	}
+
+	serverErr := make(chan error, 1)
+	go func() {
+		slog.Info("Starting server", "port", cfg.APIPort)
+		serverErr <- server.ListenAndServe()
+	}()
+
+	var serveErr error
+	select {
+	case serveErr = <-serverErr:
+		slog.Error("Server failed to start", "error", serveErr)
+		stop()
+	case <-ctx.Done():
+		slog.Info("Shutdown signal received, draining connections", "timeout", cfg.ShutdownTimeout)
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+
+	if serveErr == nil {
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			slog.Error("Server shutdown did not complete", "error", err)
+		} else {
+			slog.Info("Server stopped")
+		}
+	}
+
+	if err := jobService.Stop(shutdownCtx); err != nil {
+		slog.Error("Ingestion workers did not stop in time", "error", err)
+	}
+	if err := purgeService.Stop(shutdownCtx); err != nil {
+		slog.Error("Trash purger did not stop in time", "error", err)
+	}
+	if err := lyricsBackfill.Stop(shutdownCtx); err != nil {
+		slog.Error("Lyrics sections backfill did not stop in time", "error", err)
+	}
+
+	return serveErr
 }
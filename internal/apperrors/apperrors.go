@@ -0,0 +1,61 @@
+// Package apperrors defines the domain errors shared by the repository,
+// service and handler layers.
+package apperrors
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Error kinds. Use errors.Is to test an error against them.
+var (
+	ErrNotFound   = errors.New("not found")
+	ErrDuplicate  = errors.New("already exists")
+	ErrValidation = errors.New("validation failed")
+	ErrUpstream   = errors.New("upstream provider failed")
+	ErrConflict   = errors.New("conflicts with current state")
+	// ErrPrecondition means the resource changed since the version the client based its request on.
+	ErrPrecondition = errors.New("precondition failed")
+)
+
+// Error is a domain error of a given kind. Message is safe to show to clients,
+// while the wrapped cause is kept for logs only.
+type Error struct {
+	Kind    error
+	Message string
+	Err     error
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() []error {
+	if e.Err != nil {
+		return []error{e.Kind, e.Err}
+	}
+	return []error{e.Kind}
+}
+
+// New creates a domain error of the given kind.
+func New(kind error, format string, args ...interface{}) error {
+	return &Error{Kind: kind, Message: fmt.Sprintf(format, args...)}
+}
+
+// Wrap creates a domain error of the given kind caused by err.
+func Wrap(kind error, err error, format string, args ...interface{}) error {
+	return &Error{Kind: kind, Message: fmt.Sprintf(format, args...), Err: err}
+}
+
+// PublicMessage returns the client-safe message of the first domain error in err's chain,
+// or a generic message when err carries none.
+func PublicMessage(err error) string {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr.Message
+	}
+	return "internal server error"
+}
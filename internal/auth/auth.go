@@ -0,0 +1,184 @@
+// Package auth defines roles, the authenticated principal carried in request contexts,
+// API key hashing and HS256 token verification.
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Role grants access to a set of routes. Each role includes the permissions of the ones before it.
+type Role string
+
+const (
+	RoleReader Role = "reader"
+	RoleEditor Role = "editor"
+	RoleAdmin  Role = "admin"
+)
+
+var roleRanks = map[Role]int{
+	RoleReader: 1,
+	RoleEditor: 2,
+	RoleAdmin:  3,
+}
+
+// Valid reports whether r is a known role.
+func (r Role) Valid() bool {
+	_, ok := roleRanks[r]
+	return ok
+}
+
+// Allows reports whether r grants the permissions of required.
+func (r Role) Allows(required Role) bool {
+	return r.Valid() && roleRanks[r] >= roleRanks[required]
+}
+
+// Principal is the authenticated caller of a request.
+type Principal struct {
+	// Subject identifies the caller: "key:<id>" for API keys, "user:<sub>" for tokens.
+	Subject string
+	Role    Role
+}
+
+type principalKey struct{}
+
+// WithPrincipal returns a copy of ctx carrying p.
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// FromContext returns the principal of the request, if it was authenticated.
+func FromContext(ctx context.Context) (*Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(*Principal)
+	return p, ok
+}
+
+// ErrInvalidCredentials is returned for unknown, revoked, expired or malformed credentials.
+var ErrInvalidCredentials = errors.New("invalid credentials")
+
+// KeyPrefix marks API keys issued by this service, so they can be told apart from tokens.
+const KeyPrefix = "mlk_"
+
+// GenerateKey returns a new random API key.
+func GenerateKey() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate API key: %w", err)
+	}
+	return KeyPrefix + base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// HashKey returns the hex SHA-256 digest under which an API key is stored.
+// Keys are random and long, so a fast unsalted hash is sufficient.
+func HashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// KeyDisplayPrefix returns the start of a key that is safe to show to identify it.
+func KeyDisplayPrefix(key string) string {
+	if len(key) <= len(KeyPrefix)+6 {
+		return key
+	}
+	return key[:len(KeyPrefix)+6]
+}
+
+// Configured keys must have a secret, the part after KeyPrefix, of at least minKeySecretLength
+// characters, of which at least minKeySecretDistinct differ, to rule out short and repetitive secrets.
+const (
+	minKeySecretLength   = 32
+	minKeySecretDistinct = 12
+)
+
+// CheckKeyStrength returns an error unless key is an API key with a long and varied enough
+// secret. Generated keys always pass; it guards keys supplied by operators.
+func CheckKeyStrength(key string) error {
+	if !IsAPIKey(key) {
+		return fmt.Errorf("the key must start with %q", KeyPrefix)
+	}
+
+	secret := strings.TrimPrefix(key, KeyPrefix)
+	if len(secret) < minKeySecretLength {
+		return fmt.Errorf("the key must have at least %d characters after %q", minKeySecretLength, KeyPrefix)
+	}
+
+	distinct := make(map[rune]struct{})
+	for _, c := range secret {
+		distinct[c] = struct{}{}
+	}
+	if len(distinct) < minKeySecretDistinct {
+		return fmt.Errorf("the key must use at least %d different characters after %q", minKeySecretDistinct, KeyPrefix)
+	}
+	return nil
+}
+
+// IsAPIKey reports whether credential has the form of an API key rather than a token.
+func IsAPIKey(credential string) bool {
+	return strings.HasPrefix(credential, KeyPrefix)
+}
+
+// TokenConfig holds the settings for verifying HS256 tokens.
+type TokenConfig struct {
+	Secret []byte
+	// Issuer and Audience are checked when set.
+	Issuer   string
+	Audience string
+	// Leeway tolerates clock skew between the issuer and this service.
+	Leeway time.Duration
+}
+
+// TokenVerifier verifies HS256 JSON web tokens carrying a "role" claim.
+type TokenVerifier struct {
+	cfg    TokenConfig
+	parser *jwt.Parser
+}
+
+type tokenClaims struct {
+	Role Role `json:"role"`
+	jwt.RegisteredClaims
+}
+
+func NewTokenVerifier(cfg TokenConfig) *TokenVerifier {
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(cfg.Leeway),
+	}
+	if cfg.Issuer != "" {
+		opts = append(opts, jwt.WithIssuer(cfg.Issuer))
+	}
+	if cfg.Audience != "" {
+		opts = append(opts, jwt.WithAudience(cfg.Audience))
+	}
+
+	return &TokenVerifier{
+		cfg:    cfg,
+		parser: jwt.NewParser(opts...),
+	}
+}
+
+// Verify checks the signature and claims of token and returns its principal.
+func (v *TokenVerifier) Verify(token string) (*Principal, error) {
+	var claims tokenClaims
+	_, err := v.parser.ParseWithClaims(token, &claims, func(*jwt.Token) (interface{}, error) {
+		return v.cfg.Secret, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidCredentials, err)
+	}
+
+	if claims.Subject == "" || !claims.Role.Valid() {
+		return nil, fmt.Errorf("%w: token must carry a subject and a valid role", ErrInvalidCredentials)
+	}
+
+	return &Principal{Subject: "user:" + claims.Subject, Role: claims.Role}, nil
+}
@@ -0,0 +1,143 @@
+package auth
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var testSecret = []byte("test-secret")
+
+func newTestVerifier() *TokenVerifier {
+	return NewTokenVerifier(TokenConfig{Secret: testSecret, Issuer: "issuer", Audience: "music-library"})
+}
+
+// signToken signs valid editor claims for alice, after applying changes; a nil value drops a claim.
+func signToken(t *testing.T, method jwt.SigningMethod, secret []byte, changes jwt.MapClaims) string {
+	t.Helper()
+
+	claims := jwt.MapClaims{
+		"sub":  "alice",
+		"role": "editor",
+		"iss":  "issuer",
+		"aud":  "music-library",
+		"exp":  time.Now().Add(time.Hour).Unix(),
+	}
+	for k, v := range changes {
+		if v == nil {
+			delete(claims, k)
+		} else {
+			claims[k] = v
+		}
+	}
+
+	token, err := jwt.NewWithClaims(method, claims).SignedString(secret)
+	if err != nil {
+		t.Fatalf("failed to sign token: %v", err)
+	}
+	return token
+}
+
+func TestRoleAllowsLowerRoles(t *testing.T) {
+	if !RoleAdmin.Allows(RoleEditor) || !RoleEditor.Allows(RoleReader) || !RoleReader.Allows(RoleReader) {
+		t.Error("a role must allow itself and the roles below it")
+	}
+	if RoleReader.Allows(RoleEditor) || RoleEditor.Allows(RoleAdmin) {
+		t.Error("a role must not allow the roles above it")
+	}
+}
+
+func TestRoleAllowsNothingWhenUnknown(t *testing.T) {
+	if Role("owner").Allows(RoleReader) || Role("").Allows(RoleReader) {
+		t.Error("an unknown role must not allow anything")
+	}
+}
+
+func TestGenerateKey(t *testing.T) {
+	key, err := GenerateKey()
+	if err != nil {
+		t.Fatalf("GenerateKey(): %v", err)
+	}
+	if err := CheckKeyStrength(key); err != nil {
+		t.Errorf("CheckKeyStrength(GenerateKey()) = %v, want nil", err)
+	}
+
+	other, _ := GenerateKey()
+	if other == key || HashKey(other) == HashKey(key) {
+		t.Errorf("GenerateKey() returned %q twice", key)
+	}
+}
+
+func TestCheckKeyStrengthRejectsShortSecret(t *testing.T) {
+	if err := CheckKeyStrength(KeyPrefix + "0123456789abcdef"); err == nil {
+		t.Error("CheckKeyStrength() accepted a 16 character secret")
+	}
+}
+
+func TestCheckKeyStrengthRejectsRepetitiveSecret(t *testing.T) {
+	if err := CheckKeyStrength(KeyPrefix + strings.Repeat("ab", 32)); err == nil {
+		t.Error("CheckKeyStrength() accepted a secret of two characters")
+	}
+}
+
+func TestCheckKeyStrengthRequiresPrefix(t *testing.T) {
+	if err := CheckKeyStrength("0123456789abcdefghijklmnopqrstuvwxyz"); err == nil {
+		t.Errorf("CheckKeyStrength() accepted a key without %q", KeyPrefix)
+	}
+}
+
+func TestVerifyReturnsPrincipal(t *testing.T) {
+	got, err := newTestVerifier().Verify(signToken(t, jwt.SigningMethodHS256, testSecret, nil))
+	if err != nil {
+		t.Fatalf("Verify(): %v", err)
+	}
+	if want := (Principal{Subject: "user:alice", Role: RoleEditor}); *got != want {
+		t.Errorf("Verify() = %+v, want %+v", *got, want)
+	}
+}
+
+func TestVerifyRejectsWrongSecret(t *testing.T) {
+	_, err := newTestVerifier().Verify(signToken(t, jwt.SigningMethodHS256, []byte("other"), nil))
+	if !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("Verify() = %v, want %v", err, ErrInvalidCredentials)
+	}
+}
+
+func TestVerifyRejectsOtherAlgorithm(t *testing.T) {
+	_, err := newTestVerifier().Verify(signToken(t, jwt.SigningMethodHS512, testSecret, nil))
+	if !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("Verify() of an HS512 token = %v, want %v", err, ErrInvalidCredentials)
+	}
+}
+
+func TestVerifyRequiresExpiry(t *testing.T) {
+	_, err := newTestVerifier().Verify(signToken(t, jwt.SigningMethodHS256, testSecret, jwt.MapClaims{"exp": nil}))
+	if !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("Verify() of a token without exp = %v, want %v", err, ErrInvalidCredentials)
+	}
+}
+
+func TestVerifyRejectsExpiredToken(t *testing.T) {
+	expired := jwt.MapClaims{"exp": time.Now().Add(-time.Hour).Unix()}
+	_, err := newTestVerifier().Verify(signToken(t, jwt.SigningMethodHS256, testSecret, expired))
+	if !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("Verify() of an expired token = %v, want %v", err, ErrInvalidCredentials)
+	}
+}
+
+func TestVerifyChecksAudience(t *testing.T) {
+	_, err := newTestVerifier().Verify(signToken(t, jwt.SigningMethodHS256, testSecret, jwt.MapClaims{"aud": "other"}))
+	if !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("Verify() of a token for another audience = %v, want %v", err, ErrInvalidCredentials)
+	}
+}
+
+func TestVerifyRejectsUnknownRole(t *testing.T) {
+	_, err := newTestVerifier().Verify(signToken(t, jwt.SigningMethodHS256, testSecret, jwt.MapClaims{"role": "owner"}))
+	if !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("Verify() of a token with an unknown role = %v, want %v", err, ErrInvalidCredentials)
+	}
+}
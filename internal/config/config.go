@@ -3,7 +3,13 @@ package config
 import (
 	"fmt"
 	"log/slog"
+	"music-library/internal/ratelimit"
 	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -16,6 +22,64 @@ type Config struct {
 	DBName      string
 	APIPort     string
 	ExternalAPI string
+
+	ExternalAPITimeout        time.Duration
+	ExternalAPIConnectTimeout time.Duration
+
+	ExternalAPIMaxAttempts     int
+	ExternalAPIRetryBaseDelay  time.Duration
+	ExternalAPIRetryMaxDelay   time.Duration
+	BreakerFailureThreshold    int
+	BreakerOpenTimeout         time.Duration
+	BreakerHalfOpenMaxRequests int
+
+	JobWorkers        int
+	JobPollInterval   time.Duration
+	JobLease          time.Duration
+	JobMaxAttempts    int
+	JobRetryBaseDelay time.Duration
+	JobRetryMaxDelay  time.Duration
+
+	// TrashRetention is how long deleted songs are kept before they are purged; zero keeps them.
+	TrashRetention     time.Duration
+	TrashPurgeInterval time.Duration
+
+	ImportConcurrency int
+	ImportMaxRows     int
+
+	ReadRequestTimeout         time.Duration
+	WriteRequestTimeout        time.Duration
+	ImportRequestTimeout       time.Duration
+	ExportRequestTimeout       time.Duration
+	AutocompleteRequestTimeout time.Duration
+
+	ServerReadTimeout       time.Duration
+	ServerReadHeaderTimeout time.Duration
+	ServerWriteTimeout      time.Duration
+	ServerIdleTimeout       time.Duration
+	ShutdownTimeout         time.Duration
+
+	ReadyCheckEnricher bool
+	ReadyCheckTimeout  time.Duration
+
+	// AuthJWTSecret enables HS256 token authentication when set.
+	AuthJWTSecret   string
+	AuthJWTIssuer   string
+	AuthJWTAudience string
+	AuthJWTLeeway   time.Duration
+	// AuthBootstrapKey is an admin API key registered at startup to issue the first keys.
+	AuthBootstrapKey string
+
+	RateLimitEnabled    bool
+	RateLimitRead       map[string]ratelimit.Policy
+	RateLimitWrite      map[string]ratelimit.Policy
+	RateLimitTrustProxy bool
+
+	// SearchLanguages are the text search configurations songs may use; all of them are searched.
+	SearchLanguages       []string
+	SearchDefaultLanguage string
+	// SearchSimilarityThreshold is the default minimum trigram word similarity of a fuzzy match.
+	SearchSimilarityThreshold float64
 }
 
 func LoadConfig() (*Config, error) {
@@ -55,9 +119,206 @@ func LoadConfig() (*Config, error) {
 	}
 
 	externalAPI := os.Getenv("EXTERNAL_API_URL")
-	// if externalAPI == "" {
-	// 	return nil, fmt.Errorf("the EXTERNAL_API_URL value is not set in the environment variables")
-	// }
+	if externalAPI == "" {
+		return nil, fmt.Errorf("the EXTERNAL_API_URL value is not set in the environment variables")
+	}
+
+	externalAPITimeout, err := getEnvDuration("EXTERNAL_API_TIMEOUT", 10*time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	externalAPIConnectTimeout, err := getEnvDuration("EXTERNAL_API_CONNECT_TIMEOUT", 3*time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	externalAPIMaxAttempts, err := getEnvInt("EXTERNAL_API_MAX_ATTEMPTS", 3)
+	if err != nil {
+		return nil, err
+	}
+
+	externalAPIRetryBaseDelay, err := getEnvDuration("EXTERNAL_API_RETRY_BASE_DELAY", 200*time.Millisecond)
+	if err != nil {
+		return nil, err
+	}
+
+	externalAPIRetryMaxDelay, err := getEnvDuration("EXTERNAL_API_RETRY_MAX_DELAY", 5*time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	breakerFailureThreshold, err := getEnvInt("BREAKER_FAILURE_THRESHOLD", 5)
+	if err != nil {
+		return nil, err
+	}
+
+	breakerOpenTimeout, err := getEnvDuration("BREAKER_OPEN_TIMEOUT", 30*time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	breakerHalfOpenMaxRequests, err := getEnvInt("BREAKER_HALF_OPEN_MAX_REQUESTS", 1)
+	if err != nil {
+		return nil, err
+	}
+
+	jobWorkers, err := getEnvInt("JOB_WORKERS", 2)
+	if err != nil {
+		return nil, err
+	}
+
+	jobPollInterval, err := getEnvDuration("JOB_POLL_INTERVAL", time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	jobLease, err := getEnvDuration("JOB_LEASE", 5*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+
+	jobMaxAttempts, err := getEnvInt("JOB_MAX_ATTEMPTS", 5)
+	if err != nil {
+		return nil, err
+	}
+
+	jobRetryBaseDelay, err := getEnvDuration("JOB_RETRY_BASE_DELAY", 10*time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	jobRetryMaxDelay, err := getEnvDuration("JOB_RETRY_MAX_DELAY", 10*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+
+	trashRetention, err := getEnvDuration("TRASH_RETENTION", 30*24*time.Hour)
+	if err != nil {
+		return nil, err
+	}
+
+	trashPurgeInterval, err := getEnvDuration("TRASH_PURGE_INTERVAL", time.Hour)
+	if err != nil {
+		return nil, err
+	}
+
+	importConcurrency, err := getEnvInt("IMPORT_CONCURRENCY", 4)
+	if err != nil {
+		return nil, err
+	}
+
+	importMaxRows, err := getEnvInt("IMPORT_MAX_ROWS", 10000)
+	if err != nil {
+		return nil, err
+	}
+
+	readRequestTimeout, err := getEnvDuration("READ_REQUEST_TIMEOUT", 5*time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	writeRequestTimeout, err := getEnvDuration("WRITE_REQUEST_TIMEOUT", 30*time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	importRequestTimeout, err := getEnvDuration("IMPORT_REQUEST_TIMEOUT", 10*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+
+	exportRequestTimeout, err := getEnvDuration("EXPORT_REQUEST_TIMEOUT", 30*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+
+	autocompleteRequestTimeout, err := getEnvDuration("AUTOCOMPLETE_REQUEST_TIMEOUT", 250*time.Millisecond)
+	if err != nil {
+		return nil, err
+	}
+
+	serverReadTimeout, err := getEnvDuration("SERVER_READ_TIMEOUT", 30*time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	serverReadHeaderTimeout, err := getEnvDuration("SERVER_READ_HEADER_TIMEOUT", 5*time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	serverWriteTimeout, err := getEnvDuration("SERVER_WRITE_TIMEOUT", 60*time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	serverIdleTimeout, err := getEnvDuration("SERVER_IDLE_TIMEOUT", 120*time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	shutdownTimeout, err := getEnvDuration("SHUTDOWN_TIMEOUT", 30*time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	readyCheckEnricher, err := getEnvBool("READY_CHECK_ENRICHER", false)
+	if err != nil {
+		return nil, err
+	}
+
+	readyCheckTimeout, err := getEnvDuration("READY_CHECK_TIMEOUT", 2*time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	authJWTSecret := os.Getenv("AUTH_JWT_SECRET")
+	if authJWTSecret != "" && len(authJWTSecret) < 32 {
+		return nil, fmt.Errorf("the AUTH_JWT_SECRET value must be at least 32 bytes long")
+	}
+
+	authJWTLeeway, err := getEnvDuration("AUTH_JWT_LEEWAY", 30*time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	rateLimitEnabled, err := getEnvBool("RATE_LIMIT_ENABLED", true)
+	if err != nil {
+		return nil, err
+	}
+
+	rateLimitRead, err := getEnvRateLimits("RATE_LIMIT_READ", "anonymous=1:10,reader=10:50,editor=20:100,admin=50:200")
+	if err != nil {
+		return nil, err
+	}
+
+	rateLimitWrite, err := getEnvRateLimits("RATE_LIMIT_WRITE", "anonymous=0.2:5,reader=0.2:5,editor=2:10,admin=5:20")
+	if err != nil {
+		return nil, err
+	}
+
+	rateLimitTrustProxy, err := getEnvBool("RATE_LIMIT_TRUST_PROXY", false)
+	if err != nil {
+		return nil, err
+	}
+
+	searchLanguages, err := getEnvLanguages("SEARCH_LANGUAGES", "simple,english,russian")
+	if err != nil {
+		return nil, err
+	}
+	searchDefaultLanguage := os.Getenv("SEARCH_DEFAULT_LANGUAGE")
+	if searchDefaultLanguage == "" {
+		searchDefaultLanguage = "simple"
+	}
+	if !slices.Contains(searchLanguages, searchDefaultLanguage) {
+		return nil, fmt.Errorf("the SEARCH_DEFAULT_LANGUAGE value %q is not one of SEARCH_LANGUAGES", searchDefaultLanguage)
+	}
+
+	searchSimilarityThreshold, err := getEnvFraction("SEARCH_SIMILARITY_THRESHOLD", 0.3)
+	if err != nil {
+		return nil, err
+	}
 
 	return &Config{
 		DBHost:      dbHost,
@@ -67,5 +328,158 @@ func LoadConfig() (*Config, error) {
 		DBName:      dbName,
 		APIPort:     apiPort,
 		ExternalAPI: externalAPI,
+
+		ExternalAPITimeout:        externalAPITimeout,
+		ExternalAPIConnectTimeout: externalAPIConnectTimeout,
+
+		ExternalAPIMaxAttempts:     externalAPIMaxAttempts,
+		ExternalAPIRetryBaseDelay:  externalAPIRetryBaseDelay,
+		ExternalAPIRetryMaxDelay:   externalAPIRetryMaxDelay,
+		BreakerFailureThreshold:    breakerFailureThreshold,
+		BreakerOpenTimeout:         breakerOpenTimeout,
+		BreakerHalfOpenMaxRequests: breakerHalfOpenMaxRequests,
+
+		JobWorkers:        jobWorkers,
+		JobPollInterval:   jobPollInterval,
+		JobLease:          jobLease,
+		JobMaxAttempts:    jobMaxAttempts,
+		JobRetryBaseDelay: jobRetryBaseDelay,
+		JobRetryMaxDelay:  jobRetryMaxDelay,
+
+		TrashRetention:     trashRetention,
+		TrashPurgeInterval: trashPurgeInterval,
+
+		ImportConcurrency: importConcurrency,
+		ImportMaxRows:     importMaxRows,
+
+		ReadRequestTimeout:         readRequestTimeout,
+		WriteRequestTimeout:        writeRequestTimeout,
+		ImportRequestTimeout:       importRequestTimeout,
+		ExportRequestTimeout:       exportRequestTimeout,
+		AutocompleteRequestTimeout: autocompleteRequestTimeout,
+
+		ServerReadTimeout:       serverReadTimeout,
+		ServerReadHeaderTimeout: serverReadHeaderTimeout,
+		ServerWriteTimeout:      serverWriteTimeout,
+		ServerIdleTimeout:       serverIdleTimeout,
+		ShutdownTimeout:         shutdownTimeout,
+
+		ReadyCheckEnricher: readyCheckEnricher,
+		ReadyCheckTimeout:  readyCheckTimeout,
+
+		AuthJWTSecret:    authJWTSecret,
+		AuthJWTIssuer:    os.Getenv("AUTH_JWT_ISSUER"),
+		AuthJWTAudience:  os.Getenv("AUTH_JWT_AUDIENCE"),
+		AuthJWTLeeway:    authJWTLeeway,
+		AuthBootstrapKey: os.Getenv("AUTH_BOOTSTRAP_KEY"),
+
+		RateLimitEnabled:    rateLimitEnabled,
+		RateLimitRead:       rateLimitRead,
+		RateLimitWrite:      rateLimitWrite,
+		RateLimitTrustProxy: rateLimitTrustProxy,
+
+		SearchLanguages:           searchLanguages,
+		SearchDefaultLanguage:     searchDefaultLanguage,
+		SearchSimilarityThreshold: searchSimilarityThreshold,
 	}, nil
 }
+
+// getEnvDuration reads a duration such as "5s" from the environment, falling back to def when unset.
+func getEnvDuration(key string, def time.Duration) (time.Duration, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return def, nil
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("the %s value %q is not a valid duration", key, value)
+	}
+
+	return d, nil
+}
+
+// getEnvInt reads a positive integer from the environment, falling back to def when unset.
+func getEnvInt(key string, def int) (int, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return def, nil
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("the %s value %q is not a positive integer", key, value)
+	}
+
+	return n, nil
+}
+
+// getEnvFraction reads a number greater than 0 and at most 1 from the environment, falling back to def when unset.
+func getEnvFraction(key string, def float64) (float64, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return def, nil
+	}
+
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil || f <= 0 || f > 1 {
+		return 0, fmt.Errorf("the %s value %q is not a number greater than 0 and at most 1", key, value)
+	}
+
+	return f, nil
+}
+
+// getEnvRateLimits reads per-role rate limits such as "reader=10:50,editor=20:100" from the
+// environment, falling back to def when unset.
+func getEnvRateLimits(key, def string) (map[string]ratelimit.Policy, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		value = def
+	}
+
+	policies, err := ratelimit.ParsePolicies(value)
+	if err != nil {
+		return nil, fmt.Errorf("the %s value is invalid: %w", key, err)
+	}
+
+	return policies, nil
+}
+
+// getEnvBool reads a boolean such as "true" or "0" from the environment, falling back to def when unset.
+func getEnvBool(key string, def bool) (bool, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return def, nil
+	}
+
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("the %s value %q is not a valid boolean", key, value)
+	}
+
+	return b, nil
+}
+
+// languageName matches the names of PostgreSQL text search configurations.
+var languageName = regexp.MustCompile(`^[a-z_]+$`)
+
+// getEnvLanguages reads a comma-separated list of text search configurations, falling back to def when unset.
+func getEnvLanguages(key, def string) ([]string, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		value = def
+	}
+
+	var languages []string
+	for _, language := range strings.Split(value, ",") {
+		language = strings.TrimSpace(language)
+		if !languageName.MatchString(language) {
+			return nil, fmt.Errorf("the %s value %q is not a valid text search configuration", key, language)
+		}
+		if !slices.Contains(languages, language) {
+			languages = append(languages, language)
+		}
+	}
+
+	return languages, nil
+}
@@ -0,0 +1,151 @@
+// Package fakeapi provides an in-process stand-in for the external song-info API.
+//
+// It serves GET /info?group=...&song=... with the same payload shape as the real
+// provider and can be started inside tests or run locally through cmd/fakeapi.
+package fakeapi
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SongInfo is the payload served for a song, in the provider wire format.
+type SongInfo struct {
+	ReleaseDate string `json:"releaseDate"`
+	Text        string `json:"text"`
+	Link        string `json:"link"`
+}
+
+// Server is a fake song-info provider backed by an in-memory catalog.
+type Server struct {
+	mu    sync.RWMutex
+	songs map[string]SongInfo
+
+	failures   int
+	failStatus int
+	retryAfter string
+	delay      time.Duration
+}
+
+// NewServer creates a fake provider seeded with a few well-known songs.
+func NewServer() *Server {
+	s := &Server{
+		songs: make(map[string]SongInfo),
+	}
+
+	s.AddSong("Muse", "Supermassive Black Hole", SongInfo{
+		ReleaseDate: "16.07.2006",
+		Text:        "Ooh baby, don't you know I suffer?\nOoh baby, can you hear me moan?\nYou caught me under false pretenses\nHow long before you let me go?\n\nOoh\nYou set my soul alight\nOoh\nYou set my soul alight",
+		Link:        "https://www.youtube.com/watch?v=Xsp3_a-PMTw",
+	})
+	s.AddSong("Metallica", "Nothing Else Matters", SongInfo{
+		ReleaseDate: "20.04.1992",
+		Text:        "So close, no matter how far\nCouldn't be much more from the heart\nForever trusting who we are\nAnd nothing else matters",
+		Link:        "https://www.youtube.com/watch?v=tAGnKpE4NCI",
+	})
+
+	return s
+}
+
+// NewTestServer starts the fake provider on a local loopback port.
+// The caller must Close the returned server; its URL plus "/info" is a valid EXTERNAL_API_URL.
+func NewTestServer() (*Server, *httptest.Server) {
+	s := NewServer()
+	return s, httptest.NewServer(s)
+}
+
+// AddSong registers or replaces the details served for a song.
+func (s *Server) AddSong(group, song string, info SongInfo) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.songs[key(group, song)] = info
+}
+
+// FailNext makes the next n requests fail with status, optionally sending a Retry-After header.
+func (s *Server) FailNext(n, status int, retryAfter string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures = n
+	s.failStatus = status
+	s.retryAfter = retryAfter
+}
+
+// SetDelay makes every following answer wait for d, to simulate a slow provider.
+func (s *Server) SetDelay(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.delay = d
+}
+
+// injectFailure consumes one injected failure, if any is pending.
+func (s *Server) injectFailure(w http.ResponseWriter) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.failures == 0 {
+		return false
+	}
+	s.failures--
+
+	if s.retryAfter != "" {
+		w.Header().Set("Retry-After", s.retryAfter)
+	}
+	http.Error(w, http.StatusText(s.failStatus), s.failStatus)
+	return true
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/info" {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	s.mu.RLock()
+	delay := s.delay
+	s.mu.RUnlock()
+	if delay > 0 {
+		select {
+		case <-time.After(delay):
+		case <-r.Context().Done():
+			return
+		}
+	}
+
+	if s.injectFailure(w) {
+		return
+	}
+
+	group := r.URL.Query().Get("group")
+	song := r.URL.Query().Get("song")
+	if group == "" || song == "" {
+		http.Error(w, "group and song are required", http.StatusBadRequest)
+		return
+	}
+
+	s.mu.RLock()
+	info, ok := s.songs[key(group, song)]
+	s.mu.RUnlock()
+	if !ok {
+		http.Error(w, "song not found", http.StatusNotFound)
+		return
+	}
+
+	slog.Debug("Fake provider serving song", "group", group, "song", song)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(info)
+}
+
+func key(group, song string) string {
+	return strings.ToLower(strings.TrimSpace(group)) + "\x00" + strings.ToLower(strings.TrimSpace(song))
+}
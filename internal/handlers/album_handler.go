@@ -0,0 +1,298 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"music-library/internal/models"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// AlbumService interface for interacting with the album service.
+type AlbumService interface {
+	CreateAlbum(ctx context.Context, title, artist, releaseDate, coverLink string) (*models.Album, error)
+	GetAlbum(ctx context.Context, id int64) (*models.Album, error)
+	ListAlbums(ctx context.Context, artistID int64, page, pageSize int) ([]*models.Album, error)
+	UpdateAlbum(ctx context.Context, id int64, title, artist, releaseDate, coverLink string) (*models.Album, error)
+	DeleteAlbum(ctx context.Context, id int64) error
+	GetAlbumTracks(ctx context.Context, id int64) ([]*models.Song, error)
+	AttachSong(ctx context.Context, albumID int64, songID string, disc, track int) (*models.Song, error)
+	DetachSong(ctx context.Context, albumID int64, songID string) error
+}
+
+// AlbumHandler a handler for working with albums and their tracks.
+type AlbumHandler struct {
+	service AlbumService
+}
+
+// NewAlbumHandler creates a new album handler
+func NewAlbumHandler(service AlbumService) *AlbumHandler {
+	return &AlbumHandler{
+		service: service,
+	}
+}
+
+type albumRequest struct {
+	Title       string `json:"title"`
+	Artist      string `json:"artist"`
+	ReleaseDate string `json:"release_date"`
+	CoverLink   string `json:"cover_link"`
+}
+
+// ListAlbumsHandler lists albums.
+// @Summary List albums
+// @Description Returns a page of albums, newest first, optionally of one artist.
+// @Tags albums
+// @Produce json
+// @Param artist_id query int false "Artist ID"
+// @Param page query int false "Page number"
+// @Param pageSize query int false "Page size"
+// @Success 200 {array} models.Album "List of albums"
+// @Failure 400 {object} problem "Invalid artist ID"
+// @Failure 500 {object} problem "Server error"
+// @Router /albums [get]
+func (h *AlbumHandler) ListAlbumsHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	var artistID int64
+	if value := query.Get("artist_id"); value != "" {
+		id, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || id < 1 {
+			sendProblem(w, r, http.StatusBadRequest, "Invalid artist_id")
+			return
+		}
+		artistID = id
+	}
+
+	page, _ := strconv.Atoi(query.Get("page"))
+	if page < 1 {
+		page = 1
+	}
+	pageSize, _ := strconv.Atoi(query.Get("pageSize"))
+	if pageSize < 1 {
+		pageSize = 10
+	}
+
+	albums, err := h.service.ListAlbums(r.Context(), artistID, page, pageSize)
+	if err != nil {
+		sendError(w, r, err)
+		return
+	}
+
+	sendSuccess(w, albums, http.StatusOK)
+}
+
+// CreateAlbumHandler creates an album.
+// @Summary Create an album
+// @Description Creates an album. The artist is created when it does not exist yet.
+// @Tags albums
+// @Accept json
+// @Produce json
+// @Param request body albumRequest true "Album to create"
+// @Success 201 {object} models.Album "Created album"
+// @Failure 400 {object} problem "Invalid request"
+// @Failure 409 {object} problem "Album already exists"
+// @Failure 422 {object} problem "Invalid album"
+// @Failure 500 {object} problem "Server error"
+// @Router /albums [post]
+func (h *AlbumHandler) CreateAlbumHandler(w http.ResponseWriter, r *http.Request) {
+	var request albumRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		slog.Error("Failed to decode CreateAlbum request", "error", err)
+		sendProblem(w, r, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	album, err := h.service.CreateAlbum(r.Context(), request.Title, request.Artist, request.ReleaseDate, request.CoverLink)
+	if err != nil {
+		sendError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Location", "/albums/"+strconv.FormatInt(album.ID, 10))
+	sendSuccess(w, album, http.StatusCreated)
+}
+
+// GetAlbumHandler gets an album.
+// @Summary Get an album
+// @Description Returns the album with its artist and number of tracks.
+// @Tags albums
+// @Produce json
+// @Param id path int true "Album ID"
+// @Success 200 {object} models.Album "Album information"
+// @Failure 400 {object} problem "Invalid album ID"
+// @Failure 404 {object} problem "Album not found"
+// @Failure 500 {object} problem "Server error"
+// @Router /albums/{id} [get]
+func (h *AlbumHandler) GetAlbumHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	album, err := h.service.GetAlbum(r.Context(), id)
+	if err != nil {
+		sendError(w, r, err)
+		return
+	}
+
+	sendSuccess(w, album, http.StatusOK)
+}
+
+// UpdateAlbumHandler updates an album.
+// @Summary Update an album
+// @Description Replaces the album details. Tracks without a release date inherit the album's.
+// @Tags albums
+// @Accept json
+// @Produce json
+// @Param id path int true "Album ID"
+// @Param request body albumRequest true "Album details"
+// @Success 200 {object} models.Album "Updated album"
+// @Failure 400 {object} problem "Invalid request"
+// @Failure 404 {object} problem "Album not found"
+// @Failure 409 {object} problem "Album already exists"
+// @Failure 422 {object} problem "Invalid album"
+// @Failure 500 {object} problem "Server error"
+// @Router /albums/{id} [put]
+func (h *AlbumHandler) UpdateAlbumHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	var request albumRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		slog.Error("Failed to decode UpdateAlbum request", "id", id, "error", err)
+		sendProblem(w, r, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	album, err := h.service.UpdateAlbum(r.Context(), id, request.Title, request.Artist, request.ReleaseDate, request.CoverLink)
+	if err != nil {
+		sendError(w, r, err)
+		return
+	}
+
+	sendSuccess(w, album, http.StatusOK)
+}
+
+// DeleteAlbumHandler deletes an album.
+// @Summary Delete an album
+// @Description Deletes the album. Its songs are kept and detached.
+// @Tags albums
+// @Param id path int true "Album ID"
+// @Success 204 "Successfully deleted"
+// @Failure 400 {object} problem "Invalid album ID"
+// @Failure 404 {object} problem "Album not found"
+// @Failure 500 {object} problem "Server error"
+// @Router /albums/{id} [delete]
+func (h *AlbumHandler) DeleteAlbumHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteAlbum(r.Context(), id); err != nil {
+		sendError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetAlbumTracksHandler lists the tracks of an album.
+// @Summary Album tracks
+// @Description Returns the songs of the album ordered by disc and track number.
+// @Tags albums
+// @Produce json
+// @Param id path int true "Album ID"
+// @Success 200 {array} models.Song "Track listing"
+// @Failure 400 {object} problem "Invalid album ID"
+// @Failure 404 {object} problem "Album not found"
+// @Failure 500 {object} problem "Server error"
+// @Router /albums/{id}/tracks [get]
+func (h *AlbumHandler) GetAlbumTracksHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	tracks, err := h.service.GetAlbumTracks(r.Context(), id)
+	if err != nil {
+		sendError(w, r, err)
+		return
+	}
+
+	sendSuccess(w, tracks, http.StatusOK)
+}
+
+// AttachSongHandler puts a song on an album.
+// @Summary Attach a song
+// @Description Puts the song on the album at the given disc (default 1) and track, moving it from any other album. A song without a release date inherits the album's.
+// @Tags albums
+// @Accept json
+// @Produce json
+// @Param id path int true "Album ID"
+// @Param songID path string true "Song ID"
+// @Param request body struct{ DiscNumber int `json:"disc_number"`; TrackNumber int `json:"track_number"` } true "Track position"
+// @Success 200 {object} models.Song "Attached song"
+// @Failure 400 {object} problem "Invalid request"
+// @Failure 404 {object} problem "Album or song not found"
+// @Failure 409 {object} problem "Track position is taken"
+// @Failure 422 {object} problem "Invalid track position"
+// @Failure 500 {object} problem "Server error"
+// @Router /albums/{id}/tracks/{songID} [put]
+func (h *AlbumHandler) AttachSongHandler(w http.ResponseWriter, r *http.Request) {
+	albumID, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+	songID := mux.Vars(r)["songID"]
+
+	var request struct {
+		DiscNumber  int `json:"disc_number"`
+		TrackNumber int `json:"track_number"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		slog.Error("Failed to decode AttachSong request", "album_id", albumID, "song_id", songID, "error", err)
+		sendProblem(w, r, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	song, err := h.service.AttachSong(r.Context(), albumID, songID, request.DiscNumber, request.TrackNumber)
+	if err != nil {
+		sendError(w, r, err)
+		return
+	}
+
+	sendSuccess(w, song, http.StatusOK)
+}
+
+// DetachSongHandler removes a song from an album.
+// @Summary Detach a song
+// @Description Removes the song from the album. The song itself is kept.
+// @Tags albums
+// @Param id path int true "Album ID"
+// @Param songID path string true "Song ID"
+// @Success 204 "Successfully detached"
+// @Failure 400 {object} problem "Invalid album ID"
+// @Failure 404 {object} problem "Song is not on the album"
+// @Failure 500 {object} problem "Server error"
+// @Router /albums/{id}/tracks/{songID} [delete]
+func (h *AlbumHandler) DetachSongHandler(w http.ResponseWriter, r *http.Request) {
+	albumID, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+	songID := mux.Vars(r)["songID"]
+
+	if err := h.service.DetachSong(r.Context(), albumID, songID); err != nil {
+		sendError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,321 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"music-library/internal/models"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// ArtistService interface for interacting with the artist service.
+type ArtistService interface {
+	CreateArtist(ctx context.Context, name string) (*models.Artist, error)
+	GetArtist(ctx context.Context, id int64) (*models.Artist, error)
+	ListArtists(ctx context.Context, name string, page, pageSize int) ([]*models.Artist, error)
+	RenameArtist(ctx context.Context, id int64, name string) (*models.Artist, error)
+	DeleteArtist(ctx context.Context, id int64) error
+	GetArtistSongs(ctx context.Context, id int64) ([]*models.Song, error)
+	GetSongCredits(ctx context.Context, songID string) ([]models.SongCredit, error)
+	AddSongCredit(ctx context.Context, credit models.SongCredit) (*models.SongCredit, error)
+	RemoveSongCredit(ctx context.Context, songID string, artistID int64, role models.ArtistRole) error
+}
+
+// ArtistHandler a handler for working with artists and song credits.
+type ArtistHandler struct {
+	service ArtistService
+}
+
+// NewArtistHandler creates a new artist handler
+func NewArtistHandler(service ArtistService) *ArtistHandler {
+	return &ArtistHandler{
+		service: service,
+	}
+}
+
+type artistRequest struct {
+	Name string `json:"name"`
+}
+
+// ListArtistsHandler lists artists.
+// @Summary List artists
+// @Description Returns a page of artists ordered by name, optionally filtered by a name substring.
+// @Tags artists
+// @Produce json
+// @Param name query string false "Name substring"
+// @Param page query int false "Page number"
+// @Param pageSize query int false "Page size"
+// @Success 200 {array} models.Artist "List of artists"
+// @Failure 500 {object} problem "Server error"
+// @Router /artists [get]
+func (h *ArtistHandler) ListArtistsHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	page, _ := strconv.Atoi(query.Get("page"))
+	if page < 1 {
+		page = 1
+	}
+	pageSize, _ := strconv.Atoi(query.Get("pageSize"))
+	if pageSize < 1 {
+		pageSize = 10
+	}
+
+	artists, err := h.service.ListArtists(r.Context(), query.Get("name"), page, pageSize)
+	if err != nil {
+		sendError(w, r, err)
+		return
+	}
+
+	sendSuccess(w, artists, http.StatusOK)
+}
+
+// CreateArtistHandler creates an artist.
+// @Summary Create an artist
+// @Description Creates an artist. Names differing only in case or whitespace belong to the same artist.
+// @Tags artists
+// @Accept json
+// @Produce json
+// @Param request body artistRequest true "Artist to create"
+// @Success 201 {object} models.Artist "Created artist"
+// @Failure 400 {object} problem "Invalid request"
+// @Failure 409 {object} problem "Artist already exists"
+// @Failure 422 {object} problem "Invalid artist"
+// @Failure 500 {object} problem "Server error"
+// @Router /artists [post]
+func (h *ArtistHandler) CreateArtistHandler(w http.ResponseWriter, r *http.Request) {
+	var request artistRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		slog.Error("Failed to decode CreateArtist request", "error", err)
+		sendProblem(w, r, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	artist, err := h.service.CreateArtist(r.Context(), request.Name)
+	if err != nil {
+		sendError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Location", "/artists/"+strconv.FormatInt(artist.ID, 10))
+	sendSuccess(w, artist, http.StatusCreated)
+}
+
+// GetArtistHandler gets an artist.
+// @Summary Get an artist
+// @Description Returns the artist and the number of songs it is credited on.
+// @Tags artists
+// @Produce json
+// @Param id path int true "Artist ID"
+// @Success 200 {object} models.Artist "Artist information"
+// @Failure 400 {object} problem "Invalid artist ID"
+// @Failure 404 {object} problem "Artist not found"
+// @Failure 500 {object} problem "Server error"
+// @Router /artists/{id} [get]
+func (h *ArtistHandler) GetArtistHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	artist, err := h.service.GetArtist(r.Context(), id)
+	if err != nil {
+		sendError(w, r, err)
+		return
+	}
+
+	sendSuccess(w, artist, http.StatusOK)
+}
+
+// UpdateArtistHandler renames an artist.
+// @Summary Rename an artist
+// @Description Renames the artist. Songs it is the primary artist of take the new name as their group.
+// @Tags artists
+// @Accept json
+// @Produce json
+// @Param id path int true "Artist ID"
+// @Param request body artistRequest true "New name"
+// @Success 200 {object} models.Artist "Renamed artist"
+// @Failure 400 {object} problem "Invalid request"
+// @Failure 404 {object} problem "Artist not found"
+// @Failure 409 {object} problem "Another artist has this name"
+// @Failure 422 {object} problem "Invalid artist"
+// @Failure 500 {object} problem "Server error"
+// @Router /artists/{id} [put]
+func (h *ArtistHandler) UpdateArtistHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	var request artistRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		slog.Error("Failed to decode UpdateArtist request", "id", id, "error", err)
+		sendProblem(w, r, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	artist, err := h.service.RenameArtist(r.Context(), id, request.Name)
+	if err != nil {
+		sendError(w, r, err)
+		return
+	}
+
+	sendSuccess(w, artist, http.StatusOK)
+}
+
+// DeleteArtistHandler deletes an artist.
+// @Summary Delete an artist
+// @Description Deletes an artist that is not credited on any song.
+// @Tags artists
+// @Param id path int true "Artist ID"
+// @Success 204 "Successfully deleted"
+// @Failure 400 {object} problem "Invalid artist ID"
+// @Failure 404 {object} problem "Artist not found"
+// @Failure 409 {object} problem "Artist is still credited on songs"
+// @Failure 500 {object} problem "Server error"
+// @Router /artists/{id} [delete]
+func (h *ArtistHandler) DeleteArtistHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteArtist(r.Context(), id); err != nil {
+		sendError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetArtistSongsHandler lists the songs of an artist.
+// @Summary Songs of an artist
+// @Description Returns the songs the artist is credited on in any role.
+// @Tags artists
+// @Produce json
+// @Param id path int true "Artist ID"
+// @Success 200 {array} models.Song "List of songs"
+// @Failure 400 {object} problem "Invalid artist ID"
+// @Failure 404 {object} problem "Artist not found"
+// @Failure 500 {object} problem "Server error"
+// @Router /artists/{id}/songs [get]
+func (h *ArtistHandler) GetArtistSongsHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	songs, err := h.service.GetArtistSongs(r.Context(), id)
+	if err != nil {
+		sendError(w, r, err)
+		return
+	}
+
+	sendSuccess(w, songs, http.StatusOK)
+}
+
+// GetSongCreditsHandler lists the credits of a song.
+// @Summary Song credits
+// @Description Returns the artists credited on the song with their roles, primary artist first.
+// @Tags artists
+// @Produce json
+// @Param id path string true "Song ID"
+// @Success 200 {array} models.SongCredit "List of credits"
+// @Failure 404 {object} problem "Song not found"
+// @Failure 500 {object} problem "Server error"
+// @Router /song/{id}/artists [get]
+func (h *ArtistHandler) GetSongCreditsHandler(w http.ResponseWriter, r *http.Request) {
+	songID := mux.Vars(r)["id"]
+
+	credits, err := h.service.GetSongCredits(r.Context(), songID)
+	if err != nil {
+		sendError(w, r, err)
+		return
+	}
+
+	sendSuccess(w, credits, http.StatusOK)
+}
+
+// AddSongCreditHandler credits an artist on a song.
+// @Summary Credit an artist
+// @Description Credits an artist on the song as featured artist, composer or producer. The primary artist follows the song's group.
+// @Tags artists
+// @Accept json
+// @Produce json
+// @Param id path string true "Song ID"
+// @Param request body struct{ ArtistID int64 `json:"artist_id"`; Role string `json:"role"`; Position int `json:"position"` } true "Credit to add"
+// @Success 201 {object} models.SongCredit "Added credit"
+// @Failure 400 {object} problem "Invalid request"
+// @Failure 404 {object} problem "Song or artist not found"
+// @Failure 422 {object} problem "Invalid role"
+// @Failure 500 {object} problem "Server error"
+// @Router /song/{id}/artists [post]
+func (h *ArtistHandler) AddSongCreditHandler(w http.ResponseWriter, r *http.Request) {
+	songID := mux.Vars(r)["id"]
+
+	var request struct {
+		ArtistID int64             `json:"artist_id"`
+		Role     models.ArtistRole `json:"role"`
+		Position int               `json:"position"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		slog.Error("Failed to decode AddSongCredit request", "song_id", songID, "error", err)
+		sendProblem(w, r, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	credit, err := h.service.AddSongCredit(r.Context(), models.SongCredit{
+		SongID:   songID,
+		ArtistID: request.ArtistID,
+		Role:     request.Role,
+		Position: request.Position,
+	})
+	if err != nil {
+		sendError(w, r, err)
+		return
+	}
+
+	sendSuccess(w, credit, http.StatusCreated)
+}
+
+// RemoveSongCreditHandler removes an artist's credit from a song.
+// @Summary Remove a credit
+// @Description Removes the artist's credit in the given role, or all of its secondary credits on the song when no role is given.
+// @Tags artists
+// @Param id path string true "Song ID"
+// @Param artistID path int true "Artist ID"
+// @Param role query string false "Role to remove"
+// @Success 204 "Successfully removed"
+// @Failure 400 {object} problem "Invalid artist ID"
+// @Failure 404 {object} problem "Credit not found"
+// @Failure 422 {object} problem "Invalid role"
+// @Failure 500 {object} problem "Server error"
+// @Router /song/{id}/artists/{artistID} [delete]
+func (h *ArtistHandler) RemoveSongCreditHandler(w http.ResponseWriter, r *http.Request) {
+	songID := mux.Vars(r)["id"]
+	artistID, ok := pathID(w, r, "artistID")
+	if !ok {
+		return
+	}
+
+	role := models.ArtistRole(r.URL.Query().Get("role"))
+	if err := h.service.RemoveSongCredit(r.Context(), songID, artistID, role); err != nil {
+		sendError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// pathID parses a numeric path variable, answering 400 when it is not a positive integer.
+func pathID(w http.ResponseWriter, r *http.Request, name string) (int64, bool) {
+	id, err := strconv.ParseInt(mux.Vars(r)[name], 10, 64)
+	if err != nil || id < 1 {
+		sendProblem(w, r, http.StatusBadRequest, "Invalid "+name)
+		return 0, false
+	}
+	return id, true
+}
@@ -0,0 +1,174 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"music-library/internal/auth"
+	"music-library/internal/models"
+	"net/http"
+	"strings"
+)
+
+// AuthService interface for authenticating requests and managing API keys.
+type AuthService interface {
+	Authenticate(ctx context.Context, credential string) (*auth.Principal, error)
+	IssueKey(ctx context.Context, name string, role auth.Role) (*models.APIKey, error)
+	ListKeys(ctx context.Context) ([]*models.APIKey, error)
+	RevokeKey(ctx context.Context, id int64) error
+}
+
+// AuthHandler authenticates requests and serves the API key administration endpoints.
+type AuthHandler struct {
+	service AuthService
+}
+
+// NewAuthHandler creates a new auth handler
+func NewAuthHandler(service AuthService) *AuthHandler {
+	return &AuthHandler{
+		service: service,
+	}
+}
+
+// Middleware authenticates the credential sent as "Authorization: Bearer <key or token>" or
+// "X-API-Key: <key>" and stores its principal in the request context. Requests without a
+// credential pass through unauthenticated; Require decides whether the route allows that.
+func (h *AuthHandler) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		credential := credentialFromRequest(r)
+		if credential == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		principal, err := h.service.Authenticate(r.Context(), credential)
+		if errors.Is(err, auth.ErrInvalidCredentials) {
+			slog.Warn("Rejected credentials", "path", r.URL.Path, "error", err)
+			sendUnauthorized(w, r, "invalid or revoked credentials")
+			return
+		} else if err != nil {
+			slog.Error("Failed to authenticate request", "error", err)
+			sendError(w, r, err)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(auth.WithPrincipal(r.Context(), principal)))
+	})
+}
+
+// Require allows next only to callers whose role grants role.
+func Require(role auth.Role, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		principal, ok := auth.FromContext(r.Context())
+		if !ok {
+			sendUnauthorized(w, r, "authentication required")
+			return
+		}
+		if !principal.Role.Allows(role) {
+			slog.Warn("Forbidden request", "subject", principal.Subject, "role", principal.Role, "required", role, "path", r.URL.Path)
+			sendProblem(w, r, http.StatusForbidden, "the "+string(role)+" role is required")
+			return
+		}
+
+		next(w, r)
+	}
+}
+
+func credentialFromRequest(r *http.Request) string {
+	if key := r.Header.Get("X-API-Key"); key != "" {
+		return strings.TrimSpace(key)
+	}
+
+	scheme, credential, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if ok && strings.EqualFold(scheme, "Bearer") {
+		return strings.TrimSpace(credential)
+	}
+	return ""
+}
+
+func sendUnauthorized(w http.ResponseWriter, r *http.Request, detail string) {
+	w.Header().Set("WWW-Authenticate", `Bearer realm="music-library"`)
+	sendProblem(w, r, http.StatusUnauthorized, detail)
+}
+
+// IssueKeyHandler issues an API key.
+// @Summary Issue an API key
+// @Description Creates an API key with the given role. The key is only returned in this response. Requires the admin role.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param request body struct{ Name string `json:"name"`; Role string `json:"role"` } true "Key to issue"
+// @Success 201 {object} models.APIKey "Issued key"
+// @Failure 400 {object} problem "Invalid request"
+// @Failure 401 {object} problem "Authentication required"
+// @Failure 403 {object} problem "Admin role required"
+// @Failure 422 {object} problem "Invalid name or role"
+// @Failure 500 {object} problem "Server error"
+// @Router /admin/keys [post]
+func (h *AuthHandler) IssueKeyHandler(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		Name string    `json:"name"`
+		Role auth.Role `json:"role"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		slog.Error("Failed to decode IssueKey request", "error", err)
+		sendProblem(w, r, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	key, err := h.service.IssueKey(r.Context(), request.Name, request.Role)
+	if err != nil {
+		sendError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	sendSuccess(w, key, http.StatusCreated)
+}
+
+// ListKeysHandler lists API keys.
+// @Summary List API keys
+// @Description Returns all issued API keys without their secrets, including revoked ones. Requires the admin role.
+// @Tags admin
+// @Produce json
+// @Success 200 {array} models.APIKey "List of keys"
+// @Failure 401 {object} problem "Authentication required"
+// @Failure 403 {object} problem "Admin role required"
+// @Failure 500 {object} problem "Server error"
+// @Router /admin/keys [get]
+func (h *AuthHandler) ListKeysHandler(w http.ResponseWriter, r *http.Request) {
+	keys, err := h.service.ListKeys(r.Context())
+	if err != nil {
+		sendError(w, r, err)
+		return
+	}
+
+	sendSuccess(w, keys, http.StatusOK)
+}
+
+// RevokeKeyHandler revokes an API key.
+// @Summary Revoke an API key
+// @Description Revokes the key; requests using it are rejected from now on. Requires the admin role.
+// @Tags admin
+// @Param id path int true "Key ID"
+// @Success 204 "Successfully revoked"
+// @Failure 400 {object} problem "Invalid key ID"
+// @Failure 401 {object} problem "Authentication required"
+// @Failure 403 {object} problem "Admin role required"
+// @Failure 404 {object} problem "Key not found or already revoked"
+// @Failure 500 {object} problem "Server error"
+// @Router /admin/keys/{id} [delete]
+func (h *AuthHandler) RevokeKeyHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.service.RevokeKey(r.Context(), id); err != nil {
+		sendError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
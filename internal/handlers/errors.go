@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"music-library/internal/apperrors"
+	"net/http"
+)
+
+// problem is an RFC 7807 problem details body.
+type problem struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+}
+
+// errorStatuses maps domain error kinds to HTTP statuses.
+var errorStatuses = []struct {
+	kind   error
+	status int
+}{
+	{apperrors.ErrNotFound, http.StatusNotFound},
+	{apperrors.ErrDuplicate, http.StatusConflict},
+	{apperrors.ErrConflict, http.StatusConflict},
+	{apperrors.ErrPrecondition, http.StatusPreconditionFailed},
+	{apperrors.ErrValidation, http.StatusUnprocessableEntity},
+	{apperrors.ErrUpstream, http.StatusBadGateway},
+}
+
+// statusForError returns the HTTP status for a service error.
+func statusForError(err error) int {
+	for _, e := range errorStatuses {
+		if errors.Is(err, e.kind) {
+			return e.status
+		}
+	}
+	return http.StatusInternalServerError
+}
+
+// sendError writes a service error as problem+json without exposing its internal cause.
+func sendError(w http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(err, context.DeadlineExceeded) {
+		sendProblem(w, r, http.StatusGatewayTimeout, "the request did not complete in time")
+		return
+	}
+
+	status := statusForError(err)
+	sendProblem(w, r, status, apperrors.PublicMessage(err))
+}
+
+// sendProblem writes a problem+json response with the given status and detail.
+func sendProblem(w http.ResponseWriter, r *http.Request, status int, detail string) {
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(problem{
+		Type:     "about:blank",
+		Title:    http.StatusText(status),
+		Status:   status,
+		Detail:   detail,
+		Instance: r.URL.Path,
+	}); err != nil {
+		slog.Error("Failed to encode problem response", "error", err)
+	}
+}
@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// songETag returns the strong entity tag of a song version.
+func songETag(version int) string {
+	return `"v` + strconv.Itoa(version) + `"`
+}
+
+// etagVersion returns the version of a strong song entity tag.
+func etagVersion(tag string) (int, bool) {
+	value, ok := strings.CutPrefix(tag, `"v`)
+	if !ok {
+		return 0, false
+	}
+	value, ok = strings.CutSuffix(value, `"`)
+	if !ok {
+		return 0, false
+	}
+	version, err := strconv.Atoi(value)
+	if err != nil || version < 1 {
+		return 0, false
+	}
+	return version, true
+}
+
+// etagList splits an If-Match or If-None-Match header into its entity tags.
+func etagList(header string) []string {
+	var tags []string
+	for _, tag := range strings.Split(header, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// noneMatch reports whether an If-None-Match header matches etag, using the weak comparison.
+func noneMatch(header, etag string) bool {
+	for _, tag := range etagList(header) {
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// ifMatchVersion reads the song version a write is conditioned on from If-Match. It returns zero
+// for "*", which only requires the song to exist. Weak tags never match, as If-Match uses the strong
+// comparison. current is called to resolve a list of several tags. A missing header is answered
+// with 428 and a header that cannot match with 412; ok is false then.
+func ifMatchVersion(w http.ResponseWriter, r *http.Request, current func() (int, error)) (version int, ok bool) {
+	header := r.Header.Get("If-Match")
+	if header == "" {
+		sendProblem(w, r, http.StatusPreconditionRequired, "If-Match with the song's ETag is required")
+		return 0, false
+	}
+
+	var versions []int
+	for _, tag := range etagList(header) {
+		if tag == "*" {
+			return 0, true
+		}
+		if v, ok := etagVersion(tag); ok {
+			versions = append(versions, v)
+		}
+	}
+
+	switch len(versions) {
+	case 0:
+		sendProblem(w, r, http.StatusPreconditionFailed, "If-Match does not match the song's ETag")
+		return 0, false
+	case 1:
+		return versions[0], true
+	}
+
+	version, err := current()
+	if err != nil {
+		sendError(w, r, err)
+		return 0, false
+	}
+	if !slices.Contains(versions, version) {
+		sendProblem(w, r, http.StatusPreconditionFailed, "If-Match does not match the song's ETag")
+		return 0, false
+	}
+	return version, true
+}
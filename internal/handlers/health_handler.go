@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"music-library/internal/models"
+	"music-library/internal/resilience"
+	"net/http"
+)
+
+// BreakerReporter reports the state of the upstream circuit breakers.
+type BreakerReporter interface {
+	BreakerStatuses() []resilience.BreakerStatus
+}
+
+// HealthService interface for running readiness checks.
+type HealthService interface {
+	Readiness(ctx context.Context) *models.Readiness
+}
+
+// HealthHandler a handler for service health endpoints.
+type HealthHandler struct {
+	service  HealthService
+	breakers BreakerReporter
+}
+
+// NewHealthHandler creates a new health handler
+func NewHealthHandler(service HealthService, breakers BreakerReporter) *HealthHandler {
+	return &HealthHandler{
+		service:  service,
+		breakers: breakers,
+	}
+}
+
+// LivenessHandler reports that the process is up.
+// @Summary Liveness
+// @Description Returns 200 while the process is able to serve requests.
+// @Tags health
+// @Produce json
+// @Success 200 {object} map[string]string "Alive"
+// @Router /healthz [get]
+func (h *HealthHandler) LivenessHandler(w http.ResponseWriter, r *http.Request) {
+	sendSuccess(w, map[string]string{"status": "ok"}, http.StatusOK)
+}
+
+// ReadinessHandler reports whether the service and its dependencies are ready.
+// @Summary Readiness
+// @Description Checks the database, the schema version and optionally the enrichment API, with the latency of each check.
+// @Tags health
+// @Produce json
+// @Success 200 {object} models.Readiness "Ready"
+// @Failure 503 {object} models.Readiness "Not ready"
+// @Router /readyz [get]
+func (h *HealthHandler) ReadinessHandler(w http.ResponseWriter, r *http.Request) {
+	report := h.service.Readiness(r.Context())
+
+	status := http.StatusOK
+	if report.Status != models.CheckOK {
+		status = http.StatusServiceUnavailable
+		slog.Warn("Service is not ready", "checks", report.Checks)
+	}
+
+	sendSuccess(w, report, status)
+}
+
+// UpstreamHealthHandler reports the circuit breaker state of the enrichment provider.
+// @Summary Upstream health
+// @Description Returns the circuit breaker state of every enrichment provider host.
+// @Tags health
+// @Produce json
+// @Success 200 {object} map[string]interface{} "Breaker states"
+// @Router /health/upstream [get]
+func (h *HealthHandler) UpstreamHealthHandler(w http.ResponseWriter, r *http.Request) {
+	statuses := h.breakers.BreakerStatuses()
+
+	status := "ok"
+	for _, breaker := range statuses {
+		if breaker.State != resilience.StateClosed.String() {
+			status = "degraded"
+			break
+		}
+	}
+
+	slog.Info("Upstream health requested", "status", status, "breakers", len(statuses))
+	sendSuccess(w, map[string]interface{}{
+		"status":   status,
+		"breakers": statuses,
+	}, http.StatusOK)
+}
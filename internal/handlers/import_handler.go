@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"mime"
+	"music-library/internal/models"
+	"net/http"
+	"strconv"
+)
+
+// ImportService interface for bulk song imports.
+type ImportService interface {
+	Import(ctx context.Context, r io.Reader, format string, dryRun bool, charge func(context.Context) error) (*models.ImportReport, error)
+}
+
+// ImportHandler a handler for bulk song imports.
+type ImportHandler struct {
+	service ImportService
+	limits  *RateLimiter
+}
+
+// NewImportHandler creates a new import handler. Each song an import adds takes a token
+// from the client's write budget in limits, which may be nil.
+func NewImportHandler(service ImportService, limits *RateLimiter) *ImportHandler {
+	return &ImportHandler{
+		service: service,
+		limits:  limits,
+	}
+}
+
+// importFormats maps accepted content types to import formats.
+var importFormats = map[string]string{
+	"text/csv":             "csv",
+	"application/csv":      "csv",
+	"application/x-ndjson": "ndjson",
+	"application/ndjson":   "ndjson",
+	"application/jsonl":    "ndjson",
+}
+
+// ImportSongsHandler imports a list of songs.
+// @Summary Import songs
+// @Description Imports group/song pairs from CSV or NDJSON and returns a per-row report. Every song added
+// @Description takes a token from the client's write rate limit, and the import waits for the budget to refill.
+// @Description Input that cannot be read to the end stops the import; the report then covers the rows before
+// @Description last_line and is marked aborted with the error, since those rows have already been added.
+// @Tags songs
+// @Accept text/csv
+// @Accept application/x-ndjson
+// @Produce json
+// @Param format query string false "Input format (csv or ndjson), defaults to the Content-Type"
+// @Param dry_run query bool false "Only validate the input"
+// @Success 200 {object} models.ImportReport "Import report"
+// @Failure 400 {object} problem "Invalid request"
+// @Failure 415 {object} problem "Unsupported format"
+// @Router /songs/import [post]
+func (h *ImportHandler) ImportSongsHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	format := query.Get("format")
+	if format == "" {
+		mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		format = importFormats[mediaType]
+	}
+	if format != "csv" && format != "ndjson" {
+		slog.Error("Unsupported import format", "format", format, "content_type", r.Header.Get("Content-Type"))
+		sendProblem(w, r, http.StatusUnsupportedMediaType, "Unsupported import format, use text/csv or application/x-ndjson")
+		return
+	}
+
+	dryRun := false
+	if value := query.Get("dry_run"); value != "" {
+		var err error
+		if dryRun, err = strconv.ParseBool(value); err != nil {
+			sendProblem(w, r, http.StatusBadRequest, "Invalid dry_run value")
+			return
+		}
+	}
+
+	slog.Info("Received ImportSongs request", "format", format, "dry_run", dryRun)
+
+	// Imports outlive the server-wide timeouts; the request deadline bounds them instead.
+	extendReadDeadline(w, r)
+	extendWriteDeadline(w, r)
+
+	report, err := h.service.Import(r.Context(), r.Body, format, dryRun, h.limits.WriteCharge(r))
+	if err != nil {
+		slog.Error("Failed to import songs", "error", err.Error())
+		sendError(w, r, err)
+		return
+	}
+
+	sendSuccess(w, report, http.StatusOK)
+}
@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"music-library/internal/models"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// JobService interface for interacting with the ingestion job queue.
+type JobService interface {
+	EnqueueSong(ctx context.Context, group, song string) (*models.Job, error)
+	GetJob(ctx context.Context, id string) (*models.Job, error)
+}
+
+// JobHandler a handler for working with ingestion jobs.
+type JobHandler struct {
+	service JobService
+}
+
+// NewJobHandler creates a new job handler
+func NewJobHandler(service JobService) *JobHandler {
+	return &JobHandler{
+		service: service,
+	}
+}
+
+// GetJobHandler gets the status of an ingestion job.
+// @Summary Get a job
+// @Description Returns the status, attempt count and last error of an ingestion job.
+// @Tags jobs
+// @Produce json
+// @Param id path string true "Job ID"
+// @Success 200 {object} models.Job "Job information"
+// @Failure 404 {object} problem "Job not found"
+// @Failure 500 {object} problem "Server error"
+// @Router /jobs/{id} [get]
+func (h *JobHandler) GetJobHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	slog.Info("Received GetJob request", "id", id)
+
+	job, err := h.service.GetJob(r.Context(), id)
+	if err != nil {
+		slog.Error("Failed to get job", "id", id, "error", err.Error())
+		sendError(w, r, err)
+		return
+	}
+
+	sendSuccess(w, job, http.StatusOK)
+}
+
+// wantsAsync reports whether the client asked for asynchronous processing.
+func wantsAsync(r *http.Request) bool {
+	if async, err := strconv.ParseBool(r.URL.Query().Get("async")); err == nil {
+		return async
+	}
+
+	for _, pref := range r.Header.Values("Prefer") {
+		for _, token := range strings.Split(pref, ",") {
+			if strings.EqualFold(strings.TrimSpace(token), "respond-async") {
+				return true
+			}
+		}
+	}
+
+	return false
+}
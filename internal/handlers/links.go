@@ -0,0 +1,23 @@
+package handlers
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// pageLink returns a Link header entry for rel pointing at the request URL with its query changed by set.
+func pageLink(r *http.Request, rel string, set func(url.Values)) string {
+	query := r.URL.Query()
+	set(query)
+
+	target := url.URL{Path: r.URL.Path, RawQuery: query.Encode()}
+	return "<" + target.String() + `>; rel="` + rel + `"`
+}
+
+// setLinks sets the Link header (RFC 8288) from the given entries, if there are any.
+func setLinks(w http.ResponseWriter, links []string) {
+	if len(links) > 0 {
+		w.Header().Set("Link", strings.Join(links, ", "))
+	}
+}
@@ -0,0 +1,336 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"music-library/internal/models"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// PlaylistService interface for interacting with the playlist service.
+type PlaylistService interface {
+	CreatePlaylist(ctx context.Context, name string) (*models.Playlist, error)
+	GetPlaylist(ctx context.Context, id int64) (*models.Playlist, error)
+	ListPlaylists(ctx context.Context, page, pageSize int) ([]*models.Playlist, error)
+	RenamePlaylist(ctx context.Context, id int64, name string) (*models.Playlist, error)
+	DeletePlaylist(ctx context.Context, id int64) error
+	AddSong(ctx context.Context, id int64, songID string, position int) (*models.Playlist, error)
+	RemoveSong(ctx context.Context, id int64, songID string) (*models.Playlist, error)
+	MoveSong(ctx context.Context, id int64, songID string, position, version int) (*models.Playlist, error)
+	Reorder(ctx context.Context, id int64, songIDs []string, version int) (*models.Playlist, error)
+}
+
+// PlaylistHandler a handler for working with playlists.
+type PlaylistHandler struct {
+	service PlaylistService
+}
+
+// NewPlaylistHandler creates a new playlist handler
+func NewPlaylistHandler(service PlaylistService) *PlaylistHandler {
+	return &PlaylistHandler{
+		service: service,
+	}
+}
+
+type playlistRequest struct {
+	Name string `json:"name"`
+}
+
+// ListPlaylistsHandler lists playlists.
+// @Summary List playlists
+// @Description Returns a page of playlists, most recently changed first, without their songs.
+// @Tags playlists
+// @Produce json
+// @Param page query int false "Page number"
+// @Param pageSize query int false "Page size"
+// @Success 200 {array} models.Playlist "List of playlists"
+// @Failure 500 {object} problem "Server error"
+// @Router /playlists [get]
+func (h *PlaylistHandler) ListPlaylistsHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	page, _ := strconv.Atoi(query.Get("page"))
+	if page < 1 {
+		page = 1
+	}
+	pageSize, _ := strconv.Atoi(query.Get("pageSize"))
+	if pageSize < 1 {
+		pageSize = 10
+	}
+
+	playlists, err := h.service.ListPlaylists(r.Context(), page, pageSize)
+	if err != nil {
+		sendError(w, r, err)
+		return
+	}
+
+	sendSuccess(w, playlists, http.StatusOK)
+}
+
+// CreatePlaylistHandler creates a playlist.
+// @Summary Create a playlist
+// @Description Creates an empty playlist.
+// @Tags playlists
+// @Accept json
+// @Produce json
+// @Param request body playlistRequest true "Playlist to create"
+// @Success 201 {object} models.Playlist "Created playlist"
+// @Failure 400 {object} problem "Invalid request"
+// @Failure 422 {object} problem "Invalid playlist"
+// @Failure 500 {object} problem "Server error"
+// @Router /playlists [post]
+func (h *PlaylistHandler) CreatePlaylistHandler(w http.ResponseWriter, r *http.Request) {
+	var request playlistRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		slog.Error("Failed to decode CreatePlaylist request", "error", err)
+		sendProblem(w, r, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	playlist, err := h.service.CreatePlaylist(r.Context(), request.Name)
+	if err != nil {
+		sendError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Location", "/playlists/"+strconv.FormatInt(playlist.ID, 10))
+	sendSuccess(w, playlist, http.StatusCreated)
+}
+
+// GetPlaylistHandler gets a playlist.
+// @Summary Get a playlist
+// @Description Returns the playlist with its songs in order.
+// @Tags playlists
+// @Produce json
+// @Param id path int true "Playlist ID"
+// @Success 200 {object} models.Playlist "Playlist with songs"
+// @Failure 400 {object} problem "Invalid playlist ID"
+// @Failure 404 {object} problem "Playlist not found"
+// @Failure 500 {object} problem "Server error"
+// @Router /playlists/{id} [get]
+func (h *PlaylistHandler) GetPlaylistHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	playlist, err := h.service.GetPlaylist(r.Context(), id)
+	if err != nil {
+		sendError(w, r, err)
+		return
+	}
+
+	sendSuccess(w, playlist, http.StatusOK)
+}
+
+// RenamePlaylistHandler renames a playlist.
+// @Summary Rename a playlist
+// @Description Renames the playlist.
+// @Tags playlists
+// @Accept json
+// @Produce json
+// @Param id path int true "Playlist ID"
+// @Param request body playlistRequest true "New name"
+// @Success 200 {object} models.Playlist "Renamed playlist"
+// @Failure 400 {object} problem "Invalid request"
+// @Failure 404 {object} problem "Playlist not found"
+// @Failure 422 {object} problem "Invalid playlist"
+// @Failure 500 {object} problem "Server error"
+// @Router /playlists/{id} [put]
+func (h *PlaylistHandler) RenamePlaylistHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	var request playlistRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		slog.Error("Failed to decode RenamePlaylist request", "id", id, "error", err)
+		sendProblem(w, r, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	playlist, err := h.service.RenamePlaylist(r.Context(), id, request.Name)
+	if err != nil {
+		sendError(w, r, err)
+		return
+	}
+
+	sendSuccess(w, playlist, http.StatusOK)
+}
+
+// DeletePlaylistHandler deletes a playlist.
+// @Summary Delete a playlist
+// @Description Deletes the playlist. Its songs are kept in the library.
+// @Tags playlists
+// @Param id path int true "Playlist ID"
+// @Success 204 "Successfully deleted"
+// @Failure 400 {object} problem "Invalid playlist ID"
+// @Failure 404 {object} problem "Playlist not found"
+// @Failure 500 {object} problem "Server error"
+// @Router /playlists/{id} [delete]
+func (h *PlaylistHandler) DeletePlaylistHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeletePlaylist(r.Context(), id); err != nil {
+		sendError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// AddPlaylistSongHandler adds a song to a playlist.
+// @Summary Add a song to a playlist
+// @Description Inserts the song at the given 1-based position, or appends it when no position is given.
+// @Tags playlists
+// @Accept json
+// @Produce json
+// @Param id path int true "Playlist ID"
+// @Param request body struct{ SongID string `json:"song_id"`; Position int `json:"position"` } true "Song to add"
+// @Success 200 {object} models.Playlist "Updated playlist"
+// @Failure 400 {object} problem "Invalid request"
+// @Failure 404 {object} problem "Playlist or song not found"
+// @Failure 409 {object} problem "Song is already in the playlist"
+// @Failure 422 {object} problem "Invalid position"
+// @Failure 500 {object} problem "Server error"
+// @Router /playlists/{id}/songs [post]
+func (h *PlaylistHandler) AddPlaylistSongHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	var request struct {
+		SongID   string `json:"song_id"`
+		Position int    `json:"position"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil || request.SongID == "" {
+		slog.Error("Invalid AddPlaylistSong request", "id", id, "error", err)
+		sendProblem(w, r, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	playlist, err := h.service.AddSong(r.Context(), id, request.SongID, request.Position)
+	if err != nil {
+		sendError(w, r, err)
+		return
+	}
+
+	sendSuccess(w, playlist, http.StatusOK)
+}
+
+// RemovePlaylistSongHandler removes a song from a playlist.
+// @Summary Remove a song from a playlist
+// @Description Removes the song; the songs after it move up one position.
+// @Tags playlists
+// @Produce json
+// @Param id path int true "Playlist ID"
+// @Param songID path string true "Song ID"
+// @Success 200 {object} models.Playlist "Updated playlist"
+// @Failure 400 {object} problem "Invalid playlist ID"
+// @Failure 404 {object} problem "Playlist not found or song not in it"
+// @Failure 500 {object} problem "Server error"
+// @Router /playlists/{id}/songs/{songID} [delete]
+func (h *PlaylistHandler) RemovePlaylistSongHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	playlist, err := h.service.RemoveSong(r.Context(), id, mux.Vars(r)["songID"])
+	if err != nil {
+		sendError(w, r, err)
+		return
+	}
+
+	sendSuccess(w, playlist, http.StatusOK)
+}
+
+// MovePlaylistSongHandler moves a song within a playlist.
+// @Summary Move a song
+// @Description Moves the song to the given 1-based position. When version is given it must match the playlist's.
+// @Tags playlists
+// @Accept json
+// @Produce json
+// @Param id path int true "Playlist ID"
+// @Param songID path string true "Song ID"
+// @Param request body struct{ Position int `json:"position"`; Version int `json:"version"` } true "Target position"
+// @Success 200 {object} models.Playlist "Updated playlist"
+// @Failure 400 {object} problem "Invalid request"
+// @Failure 404 {object} problem "Playlist not found or song not in it"
+// @Failure 409 {object} problem "Playlist was modified concurrently"
+// @Failure 422 {object} problem "Invalid position"
+// @Failure 500 {object} problem "Server error"
+// @Router /playlists/{id}/songs/{songID}/position [put]
+func (h *PlaylistHandler) MovePlaylistSongHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	var request struct {
+		Position int `json:"position"`
+		Version  int `json:"version"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		slog.Error("Failed to decode MovePlaylistSong request", "id", id, "error", err)
+		sendProblem(w, r, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	playlist, err := h.service.MoveSong(r.Context(), id, mux.Vars(r)["songID"], request.Position, request.Version)
+	if err != nil {
+		sendError(w, r, err)
+		return
+	}
+
+	sendSuccess(w, playlist, http.StatusOK)
+}
+
+// ReorderPlaylistHandler replaces the order of a playlist.
+// @Summary Reorder a playlist
+// @Description Replaces the song order. song_ids must list every song of the playlist once, and version must be the one the client last read.
+// @Tags playlists
+// @Accept json
+// @Produce json
+// @Param id path int true "Playlist ID"
+// @Param request body struct{ SongIDs []string `json:"song_ids"`; Version int `json:"version"` } true "New order"
+// @Success 200 {object} models.Playlist "Updated playlist"
+// @Failure 400 {object} problem "Invalid request"
+// @Failure 404 {object} problem "Playlist not found"
+// @Failure 409 {object} problem "Playlist was modified concurrently"
+// @Failure 422 {object} problem "Order does not match the playlist"
+// @Failure 500 {object} problem "Server error"
+// @Router /playlists/{id}/order [put]
+func (h *PlaylistHandler) ReorderPlaylistHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	var request struct {
+		SongIDs []string `json:"song_ids"`
+		Version int      `json:"version"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		slog.Error("Failed to decode ReorderPlaylist request", "id", id, "error", err)
+		sendProblem(w, r, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	playlist, err := h.service.Reorder(r.Context(), id, request.SongIDs, request.Version)
+	if err != nil {
+		sendError(w, r, err)
+		return
+	}
+
+	sendSuccess(w, playlist, http.StatusOK)
+}
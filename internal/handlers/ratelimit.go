@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"music-library/internal/auth"
+	"music-library/internal/ratelimit"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RateLimiter applies per-client token buckets, with separate budgets for reads and for
+// writes, which include the routes that call the song-info provider. Authenticated clients
+// are keyed by their credential and limited by the policy of their role; anonymous clients
+// are keyed by IP address.
+type RateLimiter struct {
+	read       *ratelimit.Limiter
+	write      *ratelimit.Limiter
+	trustProxy bool
+}
+
+// NewRateLimiter creates a rate limiter. With trustProxy the client IP is the last X-Forwarded-For
+// address, the one appended by the proxy in front of the service.
+func NewRateLimiter(read, write *ratelimit.Limiter, trustProxy bool) *RateLimiter {
+	return &RateLimiter{
+		read:       read,
+		write:      write,
+		trustProxy: trustProxy,
+	}
+}
+
+// Read limits next with the read budget. A nil RateLimiter does not limit.
+func (l *RateLimiter) Read(next http.HandlerFunc) http.HandlerFunc {
+	if l == nil {
+		return next
+	}
+	return l.limit(l.read, "read", next)
+}
+
+// Write limits next with the write budget. A nil RateLimiter does not limit.
+func (l *RateLimiter) Write(next http.HandlerFunc) http.HandlerFunc {
+	if l == nil {
+		return next
+	}
+	return l.limit(l.write, "write", next)
+}
+
+// WriteCharge returns a function taking a further token from the write budget of the client
+// of r, waiting for one when the budget is exhausted. Bulk imports charge each song they add
+// with it. A nil RateLimiter returns nil.
+func (l *RateLimiter) WriteCharge(r *http.Request) func(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+	role, client := l.client(r)
+	return func(ctx context.Context) error {
+		return l.write.Wait(ctx, role, client)
+	}
+}
+
+func (l *RateLimiter) limit(limiter *ratelimit.Limiter, budget string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		role, client := l.client(r)
+
+		d := limiter.Allow(role, client)
+		if d.Limit > 0 {
+			w.Header().Set("RateLimit-Limit", strconv.Itoa(d.Limit))
+			w.Header().Set("RateLimit-Remaining", strconv.Itoa(d.Remaining))
+			w.Header().Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(d.Reset)))
+		}
+
+		if !d.Allowed {
+			slog.Warn("Rate limit exceeded", "client", client, "role", role, "budget", budget, "path", r.URL.Path)
+			w.Header().Set("Retry-After", strconv.Itoa(ceilSeconds(d.RetryAfter)))
+			sendProblem(w, r, http.StatusTooManyRequests, "the "+budget+" rate limit of this client is exhausted")
+			return
+		}
+
+		next(w, r)
+	}
+}
+
+// client returns the role whose policy applies to r and the key of its bucket.
+func (l *RateLimiter) client(r *http.Request) (role, client string) {
+	if principal, ok := auth.FromContext(r.Context()); ok {
+		return string(principal.Role), principal.Subject
+	}
+	return ratelimit.Anonymous, "ip:" + l.clientIP(r)
+}
+
+// clientIP returns the address of the anonymous client. Entries to the left of the last
+// X-Forwarded-For address are supplied by the client and cannot be trusted.
+func (l *RateLimiter) clientIP(r *http.Request) string {
+	if l.trustProxy {
+		if values := r.Header.Values("X-Forwarded-For"); len(values) > 0 {
+			forwarded := values[len(values)-1]
+			if i := strings.LastIndex(forwarded, ","); i >= 0 {
+				forwarded = forwarded[i+1:]
+			}
+			if ip := strings.TrimSpace(forwarded); ip != "" {
+				return ip
+			}
+		}
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
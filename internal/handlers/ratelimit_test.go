@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"music-library/internal/ratelimit"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClientIPUsesPeerAddress(t *testing.T) {
+	l := NewRateLimiter(nil, nil, false)
+	r := httptest.NewRequest(http.MethodGet, "/songs", nil)
+	r.RemoteAddr = "192.0.2.1:1234"
+	r.Header.Set("X-Forwarded-For", "198.51.100.7")
+
+	if got := l.clientIP(r); got != "192.0.2.1" {
+		t.Errorf("clientIP() without a trusted proxy = %q, want the peer address", got)
+	}
+}
+
+func TestClientIPTakesLastForwardedAddress(t *testing.T) {
+	l := NewRateLimiter(nil, nil, true)
+	r := httptest.NewRequest(http.MethodGet, "/songs", nil)
+	r.Header.Set("X-Forwarded-For", "203.0.113.9, 198.51.100.7")
+
+	if got := l.clientIP(r); got != "198.51.100.7" {
+		t.Errorf("clientIP() = %q, want the address added by the proxy", got)
+	}
+}
+
+func TestLimitRejectsExhaustedClient(t *testing.T) {
+	limiter := ratelimit.NewLimiter(map[string]ratelimit.Policy{ratelimit.Anonymous: {Rate: 0.001, Burst: 1}})
+	handler := NewRateLimiter(limiter, limiter, false).Read(func(w http.ResponseWriter, r *http.Request) {})
+
+	for i, want := range []int{http.StatusOK, http.StatusTooManyRequests} {
+		rec := httptest.NewRecorder()
+		handler(rec, httptest.NewRequest(http.MethodGet, "/songs", nil))
+		if rec.Code != want {
+			t.Fatalf("request %d: status %d, want %d", i+1, rec.Code, want)
+		}
+	}
+}
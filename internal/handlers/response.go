@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"encoding/json"
+	"log/slog"
 	"net/http"
 )
 
@@ -12,3 +13,21 @@ func sendSuccess(w http.ResponseWriter, data interface{}, statusCode int) {
 		http.Error(w, "Error encoding response", http.StatusInternalServerError)
 	}
 }
+
+// extendWriteDeadline replaces the server-wide write timeout of a long-running response
+// with the request deadline, or removes it when the request has none.
+func extendWriteDeadline(w http.ResponseWriter, r *http.Request) {
+	deadline, _ := r.Context().Deadline()
+	if err := http.NewResponseController(w).SetWriteDeadline(deadline); err != nil {
+		slog.Warn("Unable to extend write deadline", "error", err)
+	}
+}
+
+// extendReadDeadline replaces the server-wide read timeout of a large request body
+// with the request deadline, or removes it when the request has none.
+func extendReadDeadline(w http.ResponseWriter, r *http.Request) {
+	deadline, _ := r.Context().Deadline()
+	if err := http.NewResponseController(w).SetReadDeadline(deadline); err != nil {
+		slog.Warn("Unable to extend read deadline", "error", err)
+	}
+}
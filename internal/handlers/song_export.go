@@ -0,0 +1,218 @@
+package handlers
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"music-library/internal/apperrors"
+	"music-library/internal/models"
+	"net/http"
+)
+
+// exportFlushEvery is the number of rows written between flushes to the client.
+const exportFlushEvery = 100
+
+// songExportWriter writes songs in one export format.
+type songExportWriter interface {
+	begin() error
+	write(song *models.Song) error
+	flush() error
+	end() error
+}
+
+var exportContentTypes = map[string]string{
+	"json":   "application/json",
+	"csv":    "text/csv; charset=utf-8",
+	"ndjson": "application/x-ndjson",
+}
+
+// ExportSongsHandler streams the library in a download format.
+// @Summary Export songs
+// @Description Streams every song matching the filters as JSON, CSV or NDJSON.
+// @Tags songs
+// @Produce json
+// @Produce text/csv
+// @Produce application/x-ndjson
+// @Param format query string false "Export format: json (default), csv or ndjson"
+// @Param group query []string false "Group name; repeat to match any of several" collectionFormat(multi)
+// @Param song query []string false "Song name; repeat to match any of several" collectionFormat(multi)
+// @Param match query string false "How group and song match names: contains (default, ignoring case) or exact"
+// @Param released_after query string false "Released on or after this date (YYYY-MM-DD)"
+// @Param released_before query string false "Released on or before this date (YYYY-MM-DD)"
+// @Param q query string false "Full-text search in title, group and lyrics"
+// @Param text query string false "Alias of q"
+// @Param lang query string false "Search only this text search configuration"
+// @Param sort query string false "Comma-separated sort keys, as for the song list"
+// @Success 200 {array} models.Song "Exported songs"
+// @Failure 400 {object} problem "Invalid request"
+// @Failure 500 {object} problem "Server error"
+// @Router /songs/export [get]
+func (h *SongHandler) ExportSongsHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	format := query.Get("format")
+	if format == "" {
+		format = "json"
+	}
+	contentType, ok := exportContentTypes[format]
+	if !ok {
+		sendProblem(w, r, http.StatusBadRequest, "Unsupported export format, use json, csv or ndjson")
+		return
+	}
+
+	filter, err := songFilterFromQuery(query, h.service.SearchLanguages())
+	if err != nil {
+		sendProblem(w, r, http.StatusBadRequest, apperrors.PublicMessage(err))
+		return
+	}
+	slog.Info("Received ExportSongs request", "format", format, "filter", filter)
+
+	// Exports outlive the server-wide write timeout; the request deadline bounds them instead.
+	extendWriteDeadline(w, r)
+
+	buf := bufio.NewWriter(w)
+	var out songExportWriter
+	switch format {
+	case "csv":
+		out = &csvExportWriter{w: csv.NewWriter(buf)}
+	case "ndjson":
+		out = &ndjsonExportWriter{enc: json.NewEncoder(buf)}
+	default:
+		out = &jsonExportWriter{w: buf}
+	}
+
+	flusher, _ := w.(http.Flusher)
+	started := false
+	count := 0
+
+	start := func() error {
+		started = true
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="songs.%s"`, format))
+		w.WriteHeader(http.StatusOK)
+		return out.begin()
+	}
+
+	err = h.service.ExportSongs(r.Context(), filter, func(song *models.Song) error {
+		if !started {
+			if err := start(); err != nil {
+				return err
+			}
+		}
+		if err := out.write(song); err != nil {
+			return err
+		}
+
+		count++
+		if count%exportFlushEvery == 0 {
+			if err := out.flush(); err != nil {
+				return err
+			}
+			if err := buf.Flush(); err != nil {
+				return err
+			}
+			if flusher != nil {
+				flusher.Flush()
+			}
+		}
+		return nil
+	})
+
+	if err != nil && !started {
+		slog.Error("Failed to export songs", "error", err.Error())
+		sendError(w, r, err)
+		return
+	}
+	if err != nil {
+		// The status line is already sent; the truncated body is all the client gets.
+		slog.Error("Song export aborted", "exported", count, "error", err.Error())
+		return
+	}
+
+	if !started {
+		if err := start(); err != nil {
+			slog.Error("Failed to write export", "error", err)
+			return
+		}
+	}
+	if err := out.end(); err != nil {
+		slog.Error("Failed to finish export", "error", err)
+		return
+	}
+	if err := buf.Flush(); err != nil {
+		slog.Error("Failed to flush export", "error", err)
+		return
+	}
+
+	slog.Info("Songs exported successfully", "format", format, "count", count)
+}
+
+type jsonExportWriter struct {
+	w     *bufio.Writer
+	count int
+}
+
+func (j *jsonExportWriter) begin() error {
+	_, err := j.w.WriteString("[")
+	return err
+}
+
+func (j *jsonExportWriter) write(song *models.Song) error {
+	if j.count > 0 {
+		if _, err := j.w.WriteString(","); err != nil {
+			return err
+		}
+	}
+	j.count++
+
+	data, err := json.Marshal(song)
+	if err != nil {
+		return err
+	}
+	_, err = j.w.Write(data)
+	return err
+}
+
+func (j *jsonExportWriter) flush() error { return nil }
+
+func (j *jsonExportWriter) end() error {
+	_, err := j.w.WriteString("]\n")
+	return err
+}
+
+type ndjsonExportWriter struct {
+	enc *json.Encoder
+}
+
+func (n *ndjsonExportWriter) begin() error { return nil }
+
+func (n *ndjsonExportWriter) write(song *models.Song) error {
+	return n.enc.Encode(song)
+}
+
+func (n *ndjsonExportWriter) flush() error { return nil }
+
+func (n *ndjsonExportWriter) end() error { return nil }
+
+type csvExportWriter struct {
+	w *csv.Writer
+}
+
+func (c *csvExportWriter) begin() error {
+	return c.w.Write([]string{"id", "group_name", "song_name", "release_date", "text", "link"})
+}
+
+func (c *csvExportWriter) write(song *models.Song) error {
+	return c.w.Write([]string{song.ID, song.GroupName, song.SongName, song.ReleaseDate, song.Text, song.Link})
+}
+
+func (c *csvExportWriter) flush() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+func (c *csvExportWriter) end() error {
+	return c.flush()
+}
@@ -1,49 +1,74 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
-	"fmt"
+	"io"
 	"log/slog"
+	"math"
+	"mime"
+	"music-library/internal/apperrors"
 	"music-library/internal/models"
 	"net/http"
+	"net/url"
+	"slices"
 	"strconv"
+	"strings"
 
 	"github.com/gorilla/mux"
 )
 
 // SongService interface for interacting with the song service.
 type SongService interface {
-	AddSong(group, song string) error
-	UpdateSong(id string, updateSong *models.Song) error
-	GetAllSongs() ([]*models.Song, error)
-	GetSong(id string) (*models.Song, error)
-	DeleteSong(id string) error
-	GetSongPaginated(filter map[string]string, page, pageSize int) ([]*models.Song, error)
-	GetSongTextPaginated(id string, page, pageSize int) ([]string, error)
+	AddSong(ctx context.Context, group, song string) (*models.Song, error)
+	UpdateSong(ctx context.Context, id string, updateSong *models.Song, version int) error
+	PatchSong(ctx context.Context, id string, patch *models.SongPatch, version int) (*models.Song, error)
+	GetAllSongs(ctx context.Context) ([]*models.Song, error)
+	GetSong(ctx context.Context, id string) (*models.Song, error)
+	DeleteSong(ctx context.Context, id string, version int) error
+	GetSongPaginated(ctx context.Context, filter *models.SongFilter, page, pageSize int) (*models.SongPage, error)
+	GetSongsByCursor(ctx context.Context, filter *models.SongFilter, cursor *models.SongCursor, pageSize int) (*models.SongPage, error)
+	GetSongTextPaginated(ctx context.Context, id string, page, pageSize int) ([]models.LyricsSection, int, error)
+	GetSongSections(ctx context.Context, id string, from, to int) ([]models.LyricsSection, int, error)
+	GetSongSection(ctx context.Context, id string, number int) (*models.LyricsSection, error)
+	ExportSongs(ctx context.Context, filter *models.SongFilter, fn func(*models.Song) error) error
+	GetSongHistory(ctx context.Context, id string, page, pageSize int) ([]*models.SongRevision, error)
+	RevertSong(ctx context.Context, id string, revision, version int) (*models.Song, error)
+	GetTrash(ctx context.Context, page, pageSize int) ([]*models.Song, error)
+	RestoreSong(ctx context.Context, id string, version int) (*models.Song, error)
+	FuzzySearch(ctx context.Context, q, group, song string, threshold float64, page, pageSize int) (*models.FuzzyResult, error)
+	Autocomplete(ctx context.Context, term string, limit int) ([]models.SongSuggestion, error)
+	SearchLanguages() []string
 }
 
 // SongHandler a handler for working with songs.
 type SongHandler struct {
 	service SongService
+	jobs    JobService
 }
 
 // NewSongHandler creates a new song handler
-func NewSongHandler(service SongService) *SongHandler {
+func NewSongHandler(service SongService, jobs JobService) *SongHandler {
 	return &SongHandler{
 		service: service,
+		jobs:    jobs,
 	}
 }
 
 // AddSongHandler adds a song.
 // @Summary Add a song
-// @Description Adds a new song to the library.
+// @Description Adds a new song to the library. With "Prefer: respond-async" or ?async=true the song is queued and a job is returned.
 // @Tags songs
 // @Accept json
 // @Produce json
 // @Param request body struct{ Group string `json:"group"`; Song string `json:"song"` } true "Song to add"
+// @Param async query bool false "Queue the song instead of adding it synchronously"
 // @Success 201 {string} string "Successfully added"
-// @Failure 400 {string} string "Invalid request"
-// @Failure 500 {string} string "Server error"
+// @Success 202 {object} models.Job "Queued for ingestion"
+// @Failure 400 {object} problem "Invalid request"
+// @Failure 409 {object} problem "Song already exists"
+// @Failure 502 {object} problem "Song-info provider failed"
+// @Failure 500 {object} problem "Server error"
 // @Router /songs [post]
 func (h *SongHandler) AddSongHandler(w http.ResponseWriter, r *http.Request) {
 	var request struct {
@@ -53,21 +78,35 @@ func (h *SongHandler) AddSongHandler(w http.ResponseWriter, r *http.Request) {
 
 	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
 		slog.Error("Failed to decode AddSong request", "error", err)
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		sendProblem(w, r, http.StatusBadRequest, "Invalid request body")
 		return
 	}
 
 	if request.Group == "" || request.Song == "" {
 		slog.Error("Invalid Adding song request", "group", request.Group, "song", request.Song)
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		sendProblem(w, r, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if wantsAsync(r) {
+		job, err := h.jobs.EnqueueSong(r.Context(), request.Group, request.Song)
+		if err != nil {
+			slog.Error("Failed to enqueue song", "error", err.Error())
+			sendError(w, r, err)
+			return
+		}
+
+		slog.Info("Song queued", "job_id", job.ID, "group", request.Group, "song", request.Song)
+		w.Header().Set("Location", "/jobs/"+job.ID)
+		sendSuccess(w, job, http.StatusAccepted)
 		return
 	}
 
 	slog.Info("Adding song", "group", request.Group, "song", request.Song)
 
-	if err := h.service.AddSong(request.Group, request.Song); err != nil {
+	if _, err := h.service.AddSong(r.Context(), request.Group, request.Song); err != nil {
 		slog.Error("Failed to add song", "error", err.Error())
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		sendError(w, r, err)
 		return
 	}
 
@@ -77,23 +116,32 @@ func (h *SongHandler) AddSongHandler(w http.ResponseWriter, r *http.Request) {
 
 // GetSongHandler gets information about the song.
 // @Summary Get the song
-// @Description Returns information about the song by its ID.
+// @Description Returns information about the song by its ID with its ETag. With a matching If-None-Match the song is not sent again.
 // @Tags songs
 // @Produce json
 // @Param id query string true "Song ID"
+// @Param If-None-Match header string false "ETag of a cached copy"
 // @Success 200 {object} models.Song "Song information"
-// @Failure 400 {string} string "Invalid request"
-// @Failure 500 {string} string "Server error"
+// @Success 304 "Not modified"
+// @Failure 404 {object} problem "Song not found"
+// @Failure 500 {object} problem "Server error"
 // @Router /songs [get]
 func (h *SongHandler) GetSongHandler(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
 	slog.Info("Received GetSong request", "id", id)
 
-	song, err := h.service.GetSong(id)
+	song, err := h.service.GetSong(r.Context(), id)
 	if err != nil {
 		slog.Error("Failed to get song", "id", id, "error", err.Error())
-		http.Error(w, fmt.Sprintf("Error: %s", err), http.StatusInternalServerError)
+		sendError(w, r, err)
+		return
+	}
+
+	etag := songETag(song.Version)
+	w.Header().Set("ETag", etag)
+	if noneMatch(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
 		return
 	}
 
@@ -107,15 +155,15 @@ func (h *SongHandler) GetSongHandler(w http.ResponseWriter, r *http.Request) {
 // @Tags songs
 // @Produce json
 // @Success 200 {array} models.Song "List of songs"
-// @Failure 500 {string} string "Server error"
+// @Failure 500 {object} problem "Server error"
 // @Router /songs/all [get]
 func (h *SongHandler) GetAllSongsHandler(w http.ResponseWriter, r *http.Request) {
 	slog.Info("Received GetAllSongs request")
 
-	songs, err := h.service.GetAllSongs()
+	songs, err := h.service.GetAllSongs(r.Context())
 	if err != nil {
 		slog.Error("Failed to retrieve all songs", "error", err.Error())
-		http.Error(w, fmt.Sprintf("Error: %s", err), http.StatusInternalServerError)
+		sendError(w, r, err)
 		return
 	}
 
@@ -125,33 +173,44 @@ func (h *SongHandler) GetAllSongsHandler(w http.ResponseWriter, r *http.Request)
 
 // UpdateSongHandler updates the information about the song.
 // @Summary Update Song
-// @Description Updates information about an existing song by its ID.
+// @Description Updates information about an existing song by its ID. If-Match must carry the song's ETag.
 // @Tags songs
 // @Accept json
 // @Param id query string true "Song ID"
+// @Param If-Match header string true "ETag of the song"
 // @Param song body models.Song true "Updated song information"
 // @Success 204 "Successfully updated"
-// @Failure 400 {string} string "Invalid request"
-// @Failure 500 {string} string "Server error"
+// @Failure 400 {object} problem "Invalid request"
+// @Failure 404 {object} problem "Song not found"
+// @Failure 409 {object} problem "Song already exists"
+// @Failure 412 {object} problem "Song was modified"
+// @Failure 422 {object} problem "Invalid song"
+// @Failure 428 {object} problem "If-Match missing"
+// @Failure 500 {object} problem "Server error"
 // @Router /songs [put]
 func (h *SongHandler) UpdateSongHandler(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
 	slog.Info("Received UpdateSong request", "id", id)
 
+	version, ok := ifMatchVersion(w, r, h.currentVersion(r, id))
+	if !ok {
+		return
+	}
+
 	var updateSong models.Song
 	err := json.NewDecoder(r.Body).Decode(&updateSong)
 	if err != nil {
 		slog.Error("Failed to decode UpdateSong request", "id", id, "error", err.Error())
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		sendProblem(w, r, http.StatusBadRequest, "Invalid request body")
 		return
 	}
 
 	slog.Info("Updating song", "id", id, "song", updateSong)
-	err = h.service.UpdateSong(id, &updateSong)
+	err = h.service.UpdateSong(r.Context(), id, &updateSong, version)
 	if err != nil {
 		slog.Error("Failed to update song", "id", id, "error", err.Error())
-		http.Error(w, fmt.Sprintf("Error: %s", err), http.StatusInternalServerError)
+		sendError(w, r, err)
 		return
 	}
 
@@ -159,23 +218,94 @@ func (h *SongHandler) UpdateSongHandler(w http.ResponseWriter, r *http.Request)
 	w.WriteHeader(http.StatusNoContent)
 }
 
-// DeleteSongHandler deletes the song.
+// mergePatchType is the media type of JSON merge patches (RFC 7396).
+const mergePatchType = "application/merge-patch+json"
+
+// PatchSongHandler partially updates the song.
+// @Summary Patch Song
+// @Description Applies a JSON merge patch (RFC 7396) to the song. Only the fields present are changed and validated; null clears a field. If-Match must carry the song's ETag. Returns the updated song with its new ETag.
+// @Tags songs
+// @Accept application/merge-patch+json
+// @Produce json
+// @Param id path string true "Song ID"
+// @Param If-Match header string true "ETag of the song"
+// @Param patch body object true "Fields to change"
+// @Success 200 {object} models.Song "Updated song"
+// @Failure 400 {object} problem "Invalid request"
+// @Failure 404 {object} problem "Song not found"
+// @Failure 409 {object} problem "Song already exists"
+// @Failure 412 {object} problem "Song was modified"
+// @Failure 415 {object} problem "Unsupported patch format"
+// @Failure 422 {object} problem "Invalid patch"
+// @Failure 428 {object} problem "If-Match missing"
+// @Failure 500 {object} problem "Server error"
+// @Router /song/{id} [patch]
+func (h *SongHandler) PatchSongHandler(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	slog.Info("Received PatchSong request", "id", id)
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != mergePatchType && mediaType != "application/json" {
+		w.Header().Set("Accept-Patch", mergePatchType)
+		sendProblem(w, r, http.StatusUnsupportedMediaType, "Unsupported patch format, use "+mergePatchType)
+		return
+	}
+
+	version, ok := ifMatchVersion(w, r, h.currentVersion(r, id))
+	if !ok {
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		slog.Error("Failed to read PatchSong request", "id", id, "error", err)
+		sendProblem(w, r, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	patch, err := models.ParseSongPatch(body)
+	if err != nil {
+		sendError(w, r, err)
+		return
+	}
+
+	song, err := h.service.PatchSong(r.Context(), id, patch, version)
+	if err != nil {
+		slog.Error("Failed to patch song", "id", id, "error", err.Error())
+		sendError(w, r, err)
+		return
+	}
+
+	w.Header().Set("ETag", songETag(song.Version))
+	sendSuccess(w, song, http.StatusOK)
+}
+
+// DeleteSongHandler moves the song to the trash.
 // @Summary Delete the song
-// @Description Deletes the song by its ID.
+// @Description Moves the song to the trash, from which it can be restored until it is purged. If-Match must carry the song's ETag.
 // @Tags songs
 // @Param id query string true "Song ID"
+// @Param If-Match header string true "ETag of the song"
 // @Success 204 "Successfully deleted"
-// @Failure 500 {string} string "Server error"
+// @Failure 404 {object} problem "Song not found"
+// @Failure 412 {object} problem "Song was modified"
+// @Failure 428 {object} problem "If-Match missing"
+// @Failure 500 {object} problem "Server error"
 // @Router /songs [delete]
 func (h *SongHandler) DeleteSongHandler(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
 	slog.Info("Received DeleteSong request", "id", id)
 
-	err := h.service.DeleteSong(id)
+	version, ok := ifMatchVersion(w, r, h.currentVersion(r, id))
+	if !ok {
+		return
+	}
+
+	err := h.service.DeleteSong(r.Context(), id, version)
 	if err != nil {
 		slog.Error("Failed to delete song", "id", id, "error", err.Error())
-		http.Error(w, fmt.Sprintf("Error: %s", err), http.StatusInternalServerError)
+		sendError(w, r, err)
 		return
 	}
 
@@ -183,68 +313,669 @@ func (h *SongHandler) DeleteSongHandler(w http.ResponseWriter, r *http.Request)
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// GetSongHistoryHandler lists the revisions of the song.
+// @Summary Song history
+// @Description Returns a page of the song's revisions, newest first, with who made each change, when, and the song before and after it. The history of deleted songs is kept.
+// @Tags songs
+// @Produce json
+// @Param id path string true "Song ID"
+// @Param page query int false "Page number"
+// @Param pageSize query int false "Page size, at most 100"
+// @Success 200 {array} models.SongRevision "Revisions"
+// @Failure 400 {object} problem "Invalid page or pageSize"
+// @Failure 404 {object} problem "Song has no history"
+// @Failure 500 {object} problem "Server error"
+// @Router /song/{id}/history [get]
+func (h *SongHandler) GetSongHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	page, pageSize, ok := pageParams(w, r, 10)
+	if !ok {
+		return
+	}
+
+	revisions, err := h.service.GetSongHistory(r.Context(), id, page, pageSize)
+	if err != nil {
+		sendError(w, r, err)
+		return
+	}
+
+	sendSuccess(w, revisions, http.StatusOK)
+}
+
+// RevertSongHandler restores an earlier revision of the song.
+// @Summary Revert Song
+// @Description Restores the details the song had after the given revision, recreating it if it was deleted, and records the revert as a new revision. If-Match is optional; when sent it must carry the song's ETag.
+// @Tags songs
+// @Produce json
+// @Param id path string true "Song ID"
+// @Param rev path int true "Revision to restore"
+// @Param If-Match header string false "ETag of the song"
+// @Success 200 {object} models.Song "Reverted song"
+// @Failure 400 {object} problem "Invalid revision"
+// @Failure 404 {object} problem "Revision not found"
+// @Failure 409 {object} problem "Song already exists"
+// @Failure 412 {object} problem "Song was modified"
+// @Failure 422 {object} problem "Revision deleted the song"
+// @Failure 500 {object} problem "Server error"
+// @Router /song/{id}/revert/{rev} [post]
+func (h *SongHandler) RevertSongHandler(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	revision, ok := pathID(w, r, "rev")
+	if !ok {
+		return
+	}
+	slog.Info("Received RevertSong request", "id", id, "revision", revision)
+
+	version := 0
+	if r.Header.Get("If-Match") != "" {
+		if version, ok = ifMatchVersion(w, r, h.currentVersion(r, id)); !ok {
+			return
+		}
+	}
+
+	song, err := h.service.RevertSong(r.Context(), id, int(revision), version)
+	if err != nil {
+		sendError(w, r, err)
+		return
+	}
+
+	w.Header().Set("ETag", songETag(song.Version))
+	sendSuccess(w, song, http.StatusOK)
+}
+
+// GetTrashHandler lists the deleted songs.
+// @Summary List the trash
+// @Description Returns a page of the deleted songs, most recently deleted first. They are purged once the retention period has passed.
+// @Tags songs
+// @Produce json
+// @Param page query int false "Page number"
+// @Param pageSize query int false "Page size, at most 100"
+// @Success 200 {array} models.Song "Deleted songs"
+// @Failure 400 {object} problem "Invalid page or pageSize"
+// @Failure 500 {object} problem "Server error"
+// @Router /trash [get]
+func (h *SongHandler) GetTrashHandler(w http.ResponseWriter, r *http.Request) {
+	page, pageSize, ok := pageParams(w, r, 10)
+	if !ok {
+		return
+	}
+
+	songs, err := h.service.GetTrash(r.Context(), page, pageSize)
+	if err != nil {
+		sendError(w, r, err)
+		return
+	}
+
+	sendSuccess(w, songs, http.StatusOK)
+}
+
+// RestoreSongHandler takes the song out of the trash.
+// @Summary Restore Song
+// @Description Restores a deleted song. If-Match is optional; when sent it must carry the deleted song's ETag.
+// @Tags songs
+// @Produce json
+// @Param id path string true "Song ID"
+// @Param If-Match header string false "ETag of the song"
+// @Success 200 {object} models.Song "Restored song"
+// @Failure 404 {object} problem "Song not in the trash"
+// @Failure 409 {object} problem "Song already exists"
+// @Failure 412 {object} problem "Song was modified"
+// @Failure 500 {object} problem "Server error"
+// @Router /song/{id}/restore [post]
+func (h *SongHandler) RestoreSongHandler(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	slog.Info("Received RestoreSong request", "id", id)
+
+	version, ok := 0, true
+	if r.Header.Get("If-Match") != "" {
+		// Lookups do not see songs in the trash, so a list of tags cannot be resolved.
+		single := func() (int, error) {
+			return 0, apperrors.New(apperrors.ErrPrecondition, "If-Match must carry a single ETag to restore a song")
+		}
+		if version, ok = ifMatchVersion(w, r, single); !ok {
+			return
+		}
+	}
+
+	song, err := h.service.RestoreSong(r.Context(), id, version)
+	if err != nil {
+		sendError(w, r, err)
+		return
+	}
+
+	w.Header().Set("ETag", songETag(song.Version))
+	sendSuccess(w, song, http.StatusOK)
+}
+
+// currentVersion returns a function reading the current version of the song.
+func (h *SongHandler) currentVersion(r *http.Request, id string) func() (int, error) {
+	return func() (int, error) {
+		song, err := h.service.GetSong(r.Context(), id)
+		if err != nil {
+			return 0, err
+		}
+		return song.Version, nil
+	}
+}
+
+// autocompleteMaxLimit caps the number of autocomplete matches.
+const autocompleteMaxLimit = 20
+
+// FuzzySearchHandler searches songs by name, tolerating typos.
+// @Summary Fuzzy search songs
+// @Description Returns songs whose group or song name contains words similar to the search terms, such as "Metallica" for "Metalica", best match first with the mean similarity as rank. At least one of q, group and song is required. The first page also suggests existing names close to each term in did_you_mean.
+// @Tags songs
+// @Produce json
+// @Param q query string false "Group or song name"
+// @Param group query string false "Group name"
+// @Param song query string false "Song name"
+// @Param threshold query number false "Minimum similarity of a match, greater than 0 and at most 1; defaults to SEARCH_SIMILARITY_THRESHOLD"
+// @Param page query int false "Page number"
+// @Param pageSize query int false "Page size, at most 100"
+// @Success 200 {object} models.FuzzyResult "Matching songs and suggestions"
+// @Failure 400 {object} problem "Invalid parameters"
+// @Failure 500 {object} problem "Server error"
+// @Router /songs/fuzzy [get]
+func (h *SongHandler) FuzzySearchHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	page, pageSize, ok := pageParams(w, r, 10)
+	if !ok {
+		return
+	}
+
+	var threshold float64
+	if value := query.Get("threshold"); value != "" {
+		var err error
+		if threshold, err = strconv.ParseFloat(value, 64); err != nil || threshold <= 0 || threshold > 1 {
+			sendProblem(w, r, http.StatusBadRequest, "threshold must be greater than 0 and at most 1")
+			return
+		}
+	}
+
+	slog.Info("Handling FuzzySearch request", "q", query.Get("q"), "group", query.Get("group"), "song", query.Get("song"), "page", page, "pageSize", pageSize)
+
+	result, err := h.service.FuzzySearch(r.Context(), query.Get("q"), query.Get("group"), query.Get("song"), threshold, page, pageSize)
+	if err != nil {
+		sendError(w, r, err)
+		return
+	}
+
+	sendSuccess(w, result, http.StatusOK)
+}
+
+// AutocompleteHandler completes song titles as they are typed.
+// @Summary Autocomplete songs
+// @Description Returns the songs whose group and song name best match the text typed so far, best first, for typeahead. Matching tolerates typos and partial words.
+// @Tags songs
+// @Produce json
+// @Param q query string true "Text typed so far, at least 2 characters"
+// @Param limit query int false "Number of matches, at most 20"
+// @Success 200 {array} models.SongSuggestion "Matches"
+// @Failure 400 {object} problem "Text too short"
+// @Failure 500 {object} problem "Server error"
+// @Router /songs/autocomplete [get]
+func (h *SongHandler) AutocompleteHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	limit, _ := strconv.Atoi(query.Get("limit"))
+	if limit < 1 {
+		limit = 10
+	}
+	limit = min(limit, autocompleteMaxLimit)
+
+	suggestions, err := h.service.Autocomplete(r.Context(), query.Get("q"), limit)
+	if err != nil {
+		sendError(w, r, err)
+		return
+	}
+
+	sendSuccess(w, suggestions, http.StatusOK)
+}
+
+// songListParams are the query parameters of the paged song list.
+var songListParams = []string{
+	"cursor", "page", "pageSize", "group", "song", "match", "released_after", "released_before",
+	"q", "text", "lang", "sort", "fields", "count",
+}
+
+// GetSongPaginated lists songs.
+// @Summary List songs
+// @Description Returns songs matching the filters, newest first with undated songs last and the ID as tiebreaker. Pages are walked with the opaque cursors of the next and prev links in the Link header; page selects a page by offset instead, for compatibility. With q the songs are searched by title, group and lyrics in every configured language, ordered by rank, paged by offset and returned with their rank and highlighted fields. sort replaces the order and pages by offset too. Invalid parameters are rejected with 400. Without any of these parameters every song is returned as a plain array, as before paging was added.
+// @Tags songs
+// @Produce json
+// @Param group query []string false "Group name; repeat to match any of several" collectionFormat(multi)
+// @Param song query []string false "Song name; repeat to match any of several" collectionFormat(multi)
+// @Param match query string false "How group and song match names: contains (default, ignoring case) or exact"
+// @Param released_after query string false "Released on or after this date (YYYY-MM-DD)"
+// @Param released_before query string false "Released on or before this date (YYYY-MM-DD)"
+// @Param q query string false "Full-text search in web search syntax"
+// @Param text query string false "Alias of q"
+// @Param lang query string false "Search only this text search configuration"
+// @Param sort query string false "Comma-separated sort keys of release_date, group_name, song_name and id, each optionally followed by :asc or :desc, e.g. group_name,release_date:desc"
+// @Param fields query string false "Comma-separated fields to return, e.g. id,group_name,song_name; the lyrics are only read when text is listed"
+// @Param count query string false "How total is computed: exact (default) or estimated, which takes the query planner's estimate for large totals"
+// @Param cursor query string false "Cursor from a next or prev link"
+// @Param page query int false "Page number, instead of a cursor"
+// @Param pageSize query int false "Page size, at most 100"
+// @Success 200 {object} songList "Songs"
+// @Failure 400 {object} problem "Invalid parameters"
+// @Failure 500 {object} problem "Server error"
+// @Router /songs [get]
 func (h *SongHandler) GetSongPaginated(w http.ResponseWriter, r *http.Request) {
 	query := r.URL.Query()
-	filter := map[string]string{}
 
-	// Читаем фильтры из query-параметров
-	if group := query.Get("group"); group != "" {
-		filter["group"] = group
+	// Clients of the unpaged list send none of the list parameters and keep getting every song.
+	if !slices.ContainsFunc(songListParams, query.Has) {
+		h.GetAllSongsHandler(w, r)
+		return
 	}
-	if song := query.Get("song"); song != "" {
-		filter["song"] = song
+
+	filter, err := songFilterFromQuery(query, h.service.SearchLanguages())
+	if err == nil && query.Has("fields") {
+		filter.Fields, err = models.ParseSongFields(query.Get("fields"))
 	}
-	if text := query.Get("text"); text != "" {
-		filter["text"] = text
+	if err != nil {
+		sendProblem(w, r, http.StatusBadRequest, apperrors.PublicMessage(err))
+		return
 	}
 
-	page, _ := strconv.Atoi(query.Get("page"))
-	if page < 1 {
-		page = 1
+	pageSize, ok := pageSizeParam(w, r, 10)
+	if !ok {
+		return
 	}
-	pageSize, _ := strconv.Atoi(query.Get("pageSize"))
-	if pageSize < 1 {
-		pageSize = 10
+
+	// Search results are ordered by rank and sorts by other fields, which cursors do not cover.
+	keyset := !query.Has("page") && filter.Q == "" && len(filter.Sort) == 0
+	if keyset {
+		h.getSongsByCursor(w, r, filter, pageSize)
+		return
+	}
+	page, ok := pageParam(w, r, pageSize)
+	if !ok {
+		return
 	}
 
 	slog.Info("Handling GetSongs request", "filter", filter, "page", page, "pageSize", pageSize)
 
-	songs, err := h.service.GetSongPaginated(filter, page, pageSize)
+	result, err := h.service.GetSongPaginated(r.Context(), filter, page, pageSize)
+	if err != nil {
+		sendError(w, r, err)
+		return
+	}
+
+	list := newSongList(result, pageSize)
+	list.Page = page
+
+	setPage := func(n int64) func(url.Values) {
+		return func(q url.Values) { q.Set("page", strconv.FormatInt(n, 10)) }
+	}
+	links := []string{pageLink(r, "first", setPage(1))}
+	if page > 1 {
+		links = append(links, pageLink(r, "prev", setPage(int64(page-1))))
+	}
+	// An estimated total may be short of the songs there are, so a full page always links onwards then.
+	if int64(page) < list.TotalPages || list.TotalEstimated && len(result.Songs) == pageSize {
+		links = append(links, pageLink(r, "next", setPage(int64(page+1))))
+	}
+	if !list.TotalEstimated && list.TotalPages > 0 {
+		links = append(links, pageLink(r, "last", setPage(list.TotalPages)))
+	}
+	setLinks(w, links)
+
+	sendSongList(w, r, list, result.Songs, filter.Fields)
+}
+
+func (h *SongHandler) getSongsByCursor(w http.ResponseWriter, r *http.Request, filter *models.SongFilter, pageSize int) {
+	var cursor *models.SongCursor
+	if token := r.URL.Query().Get("cursor"); token != "" {
+		var err error
+		if cursor, err = models.ParseSongCursor(token); err != nil {
+			sendProblem(w, r, http.StatusBadRequest, "Invalid cursor")
+			return
+		}
+	}
+
+	slog.Info("Handling GetSongs request", "filter", filter, "cursor", cursor, "pageSize", pageSize)
+
+	page, err := h.service.GetSongsByCursor(r.Context(), filter, cursor, pageSize)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		sendError(w, r, err)
+		return
+	}
+
+	links := []string{pageLink(r, "first", func(q url.Values) { q.Del("cursor") })}
+	for _, l := range []struct {
+		rel    string
+		cursor *models.SongCursor
+	}{{"next", page.Next}, {"prev", page.Prev}} {
+		if l.cursor != nil {
+			links = append(links, pageLink(r, l.rel, func(q url.Values) { q.Set("cursor", l.cursor.Encode()) }))
+		}
+	}
+	setLinks(w, links)
+
+	sendSongList(w, r, newSongList(page, pageSize), page.Songs, filter.Fields)
+}
+
+// songFilterFromQuery reads the song list filters from query parameters. group and song may be
+// repeated to match any of several names, and lang must be one of languages.
+func songFilterFromQuery(query url.Values, languages []string) (*models.SongFilter, error) {
+	// Читаем фильтры из query-параметров
+	filter := &models.SongFilter{
+		Groups:         nonEmpty(query["group"]),
+		Songs:          nonEmpty(query["song"]),
+		Match:          models.MatchMode(query.Get("match")),
+		ReleasedAfter:  query.Get("released_after"),
+		ReleasedBefore: query.Get("released_before"),
+		Q:              query.Get("q"),
+		Lang:           query.Get("lang"),
+		Count:          models.CountMode(query.Get("count")),
+	}
+	if filter.Q == "" {
+		filter.Q = query.Get("text")
+	}
+	if query.Has("sort") {
+		sort, err := models.ParseSongSort(query.Get("sort"))
+		if err != nil {
+			return nil, err
+		}
+		filter.Sort = sort
+	}
+	if filter.Lang != "" && !slices.Contains(languages, filter.Lang) {
+		return nil, apperrors.New(apperrors.ErrValidation, "lang must be one of %s", strings.Join(languages, ", "))
+	}
+	// Search results are ordered by rank and sorts by other fields, which cursors do not cover.
+	if query.Has("cursor") && (query.Has("page") || filter.Q != "" || len(filter.Sort) > 0) {
+		return nil, apperrors.New(apperrors.ErrValidation, "cursor cannot be combined with page, q or sort")
+	}
+
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+	return filter, nil
+}
+
+// nonEmpty returns the values that are not empty.
+func nonEmpty(values []string) []string {
+	var result []string
+	for _, value := range values {
+		if value != "" {
+			result = append(result, value)
+		}
+	}
+	return result
+}
+
+// positiveParam reads a positive integer query parameter, falling back to def when it is absent.
+// An invalid value is answered with 400; ok is false then.
+func positiveParam(w http.ResponseWriter, r *http.Request, name string, def int) (n int, ok bool) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return def, true
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 1 {
+		sendProblem(w, r, http.StatusBadRequest, name+" must be a positive integer")
+		return 0, false
+	}
+	return n, true
+}
+
+// Pages hold at most maxPageSize items and start at most maxPageOffset items in, which keeps
+// the offset of any page well inside an int.
+const (
+	maxPageSize   = 100
+	maxPageOffset = math.MaxInt32
+)
+
+// pageSizeParam reads the pageSize query parameter, falling back to def when it is absent.
+// A value that is not a positive integer or exceeds maxPageSize is answered with 400.
+func pageSizeParam(w http.ResponseWriter, r *http.Request, def int) (int, bool) {
+	pageSize, ok := positiveParam(w, r, "pageSize", def)
+	if ok && pageSize > maxPageSize {
+		sendProblem(w, r, http.StatusBadRequest, "pageSize must be at most "+strconv.Itoa(maxPageSize))
+		return 0, false
+	}
+	return pageSize, ok
+}
+
+// pageParam reads the page query parameter for pages of pageSize items, falling back to the
+// first page. A page starting beyond maxPageOffset is answered with 400.
+func pageParam(w http.ResponseWriter, r *http.Request, pageSize int) (int, bool) {
+	page, ok := positiveParam(w, r, "page", 1)
+	if ok && page-1 > maxPageOffset/pageSize {
+		sendProblem(w, r, http.StatusBadRequest, "page is too far past the end of the list")
+		return 0, false
+	}
+	return page, ok
+}
+
+// pageParams reads page and pageSize with pageSizeParam and pageParam.
+func pageParams(w http.ResponseWriter, r *http.Request, defSize int) (page, pageSize int, ok bool) {
+	pageSize, ok = pageSizeParam(w, r, defSize)
+	if !ok {
+		return 0, 0, false
+	}
+	page, ok = pageParam(w, r, pageSize)
+	return page, pageSize, ok
+}
+
+// songList is the body of the song list.
+type songList struct {
+	Items []interface{} `json:"items"`
+	// Page is the page number when paging by offset.
+	Page     int   `json:"page,omitempty"`
+	PageSize int   `json:"page_size"`
+	Total    int64 `json:"total"`
+	// TotalPages is the number of pages of page_size songs.
+	TotalPages int64 `json:"total_pages"`
+	// TotalEstimated is set when total is the query planner's estimate rather than a count.
+	TotalEstimated bool `json:"total_estimated,omitempty"`
+}
+
+// newSongList returns the list metadata of page, without its items.
+func newSongList(page *models.SongPage, pageSize int) *songList {
+	return &songList{
+		PageSize:       pageSize,
+		Total:          page.Total,
+		TotalPages:     (page.Total + int64(pageSize) - 1) / int64(pageSize),
+		TotalEstimated: page.Estimated,
+	}
+}
+
+// sendSongList writes the list with the songs as its items, cut down to the given fields and the
+// id when fields are listed.
+func sendSongList(w http.ResponseWriter, r *http.Request, list *songList, songs []*models.Song, fields []string) {
+	list.Items = make([]interface{}, 0, len(songs))
+	if len(fields) == 0 {
+		for _, song := range songs {
+			list.Items = append(list.Items, song)
+		}
+		sendSuccess(w, list, http.StatusOK)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(songs)
+	for _, song := range songs {
+		data, err := json.Marshal(song)
+		if err != nil {
+			sendError(w, r, err)
+			return
+		}
+		var all map[string]json.RawMessage
+		if err := json.Unmarshal(data, &all); err != nil {
+			sendError(w, r, err)
+			return
+		}
+
+		selected := map[string]json.RawMessage{"id": all["id"]}
+		for _, field := range fields {
+			if value, ok := all[field]; ok {
+				selected[field] = value
+			}
+		}
+		list.Items = append(list.Items, selected)
+	}
+	sendSuccess(w, list, http.StatusOK)
 }
 
+// lyricsPage is the body of the paginated lyrics.
+type lyricsPage struct {
+	Items    []models.LyricsSection `json:"items"`
+	Page     int                    `json:"page"`
+	PageSize int                    `json:"page_size"`
+	// Total is the number of sections of the song.
+	Total      int `json:"total"`
+	TotalPages int `json:"total_pages"`
+}
+
+// GetSongTextPaginatedHandler pages through the lyrics of a song.
+// @Summary Get song lyrics
+// @Description Returns a page of the song's lyrics sections (verses, choruses and bridges) in order, each with its number in the song, and the number of sections.
+// @Tags songs
+// @Produce json
+// @Param id path string true "Song ID"
+// @Param page query int false "Page number"
+// @Param pageSize query int false "Sections per page, 2 by default and at most 100"
+// @Success 200 {object} lyricsPage "Lyrics sections"
+// @Failure 400 {object} problem "Invalid page or pageSize"
+// @Failure 404 {object} problem "Song not found"
+// @Failure 500 {object} problem "Server error"
+// @Router /song/{id}/lyrics [get]
 func (h *SongHandler) GetSongTextPaginatedHandler(w http.ResponseWriter, r *http.Request) {
 	query := r.URL.Query()
-	id := query.Get("id")
+	// The song is named in the path, or in the query on the older /song/lyrics?id= route.
+	id := mux.Vars(r)["id"]
+	if id == "" {
+		id = query.Get("id")
+	}
 
 	if id == "" {
-		http.Error(w, "Missing song ID", http.StatusBadRequest)
+		sendProblem(w, r, http.StatusBadRequest, "Missing song ID")
 		return
 	}
 
-	page, _ := strconv.Atoi(query.Get("page"))
-	if page < 1 {
-		page = 1
-	}
-	pageSize, _ := strconv.Atoi(query.Get("pageSize"))
-	if pageSize < 1 {
-		pageSize = 2
+	page, pageSize, ok := pageParams(w, r, 2)
+	if !ok {
+		return
 	}
 
 	slog.Info("Handling GetSongTextPaginated request", "id", id, "page", page, "pageSize", pageSize)
 
-	verses, err := h.service.GetSongTextPaginated(id, page, pageSize)
+	verses, total, err := h.service.GetSongTextPaginated(r.Context(), id, page, pageSize)
+	if err != nil {
+		sendError(w, r, err)
+		return
+	}
+
+	result := lyricsPage{
+		Items:      verses,
+		Page:       page,
+		PageSize:   pageSize,
+		Total:      total,
+		TotalPages: (total + pageSize - 1) / pageSize,
+	}
+
+	setPage := func(n int) func(url.Values) {
+		return func(q url.Values) { q.Set("page", strconv.Itoa(n)) }
+	}
+	links := []string{pageLink(r, "first", setPage(1))}
+	if page > 1 {
+		links = append(links, pageLink(r, "prev", setPage(page-1)))
+	}
+	if page < result.TotalPages {
+		links = append(links, pageLink(r, "next", setPage(page+1)))
+	}
+	if result.TotalPages > 0 {
+		links = append(links, pageLink(r, "last", setPage(result.TotalPages)))
+	}
+	setLinks(w, links)
+
+	sendSuccess(w, result, http.StatusOK)
+}
+
+// maxSectionNumber bounds section numbers to the range of the database column.
+const maxSectionNumber = math.MaxInt32
+
+// sectionList is the body of a range of lyrics sections.
+type sectionList struct {
+	Items []models.LyricsSection `json:"items"`
+	// Total is the number of sections of the song.
+	Total int `json:"total"`
+}
+
+// GetSongSectionsHandler returns a range of lyrics sections.
+// @Summary Get lyrics sections
+// @Description Returns the song's lyrics sections numbered from through to, inclusively, all of them by default, and the number of sections.
+// @Tags songs
+// @Produce json
+// @Param id path string true "Song ID"
+// @Param from query int false "First section, 1 by default"
+// @Param to query int false "Last section, the song's last by default"
+// @Success 200 {object} sectionList "Lyrics sections"
+// @Failure 400 {object} problem "Invalid range"
+// @Failure 404 {object} problem "Song not found"
+// @Failure 500 {object} problem "Server error"
+// @Router /song/{id}/sections [get]
+func (h *SongHandler) GetSongSectionsHandler(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	from, ok := positiveParam(w, r, "from", 1)
+	if !ok {
+		return
+	}
+	to, ok := positiveParam(w, r, "to", 0)
+	if !ok {
+		return
+	}
+	if from > maxSectionNumber || to > maxSectionNumber {
+		sendProblem(w, r, http.StatusBadRequest, "from and to must be at most "+strconv.Itoa(maxSectionNumber))
+		return
+	}
+	if to != 0 && to < from {
+		sendProblem(w, r, http.StatusBadRequest, "to must not be less than from")
+		return
+	}
+
+	sections, total, err := h.service.GetSongSections(r.Context(), id, from, to)
+	if err != nil {
+		sendError(w, r, err)
+		return
+	}
+
+	sendSuccess(w, sectionList{Items: sections, Total: total}, http.StatusOK)
+}
+
+// GetSongSectionHandler returns a single lyrics section.
+// @Summary Get a lyrics section
+// @Description Returns the song's lyrics section with the given number, counting from 1.
+// @Tags songs
+// @Produce json
+// @Param id path string true "Song ID"
+// @Param number path int true "Section number"
+// @Success 200 {object} models.LyricsSection "Lyrics section"
+// @Failure 400 {object} problem "Invalid section number"
+// @Failure 404 {object} problem "Song or section not found"
+// @Failure 500 {object} problem "Server error"
+// @Router /song/{id}/sections/{number} [get]
+func (h *SongHandler) GetSongSectionHandler(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	number, ok := pathID(w, r, "number")
+	if !ok {
+		return
+	}
+	if number > maxSectionNumber {
+		sendProblem(w, r, http.StatusBadRequest, "number must be at most "+strconv.Itoa(maxSectionNumber))
+		return
+	}
+
+	section, err := h.service.GetSongSection(r.Context(), id, int(number))
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		sendError(w, r, err)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(verses)
+	sendSuccess(w, section, http.StatusOK)
 }
@@ -0,0 +1,211 @@
+package handlers_test
+
+import (
+	"context"
+	"music-library/internal/apperrors"
+	"music-library/internal/fakeapi"
+	"music-library/internal/handlers"
+	"music-library/internal/models"
+	"music-library/internal/resilience"
+	"music-library/internal/services"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// memorySongs keeps added songs in memory. Only the methods the tests reach are implemented;
+// the embedded interface is nil, so any other call fails the test with a panic.
+type memorySongs struct {
+	services.SongRepository
+
+	mu    sync.Mutex
+	songs map[string]models.Song
+}
+
+func (m *memorySongs) AddSongRepository(_ context.Context, song models.Song) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, s := range m.songs {
+		if s.GroupName == song.GroupName && s.SongName == song.SongName {
+			return apperrors.New(apperrors.ErrDuplicate, "song %q by %q already exists", song.SongName, song.GroupName)
+		}
+	}
+	m.songs[song.ID] = song
+	return nil
+}
+
+// newTestSongHandler returns a song handler enriching songs from a fake provider, which the
+// caller may tell to fail.
+func newTestSongHandler(t *testing.T) (*handlers.SongHandler, *fakeapi.Server) {
+	t.Helper()
+
+	provider, server := fakeapi.NewTestServer()
+	t.Cleanup(server.Close)
+
+	enricher, err := services.NewHTTPEnricher(services.HTTPEnricherConfig{
+		BaseURL: server.URL + "/info",
+		Timeout: 5 * time.Second,
+		Retry:   resilience.RetryPolicy{MaxAttempts: 1},
+		Breaker: resilience.BreakerConfig{FailureThreshold: 100, OpenTimeout: time.Minute},
+	})
+	if err != nil {
+		t.Fatalf("NewHTTPEnricher(): %v", err)
+	}
+
+	service := services.NewSongService(&memorySongs{songs: map[string]models.Song{}}, enricher, services.SearchConfig{
+		Languages:       []string{"simple", "english"},
+		DefaultLanguage: "simple",
+	})
+	return handlers.NewSongHandler(service, nil), provider
+}
+
+func postSong(handler *handlers.SongHandler, body string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	handler.AddSongHandler(rec, httptest.NewRequest(http.MethodPost, "/song", strings.NewReader(body)))
+	return rec
+}
+
+func TestAddSongCreatesKnownSong(t *testing.T) {
+	handler, _ := newTestSongHandler(t)
+
+	if rec := postSong(handler, `{"group": "Muse", "song": "Supermassive Black Hole"}`); rec.Code != http.StatusCreated {
+		t.Errorf("POST /song = %d, want %d: %s", rec.Code, http.StatusCreated, rec.Body)
+	}
+}
+
+func TestAddSongRepeatedIsConflict(t *testing.T) {
+	handler, _ := newTestSongHandler(t)
+	postSong(handler, `{"group": "Muse", "song": "Supermassive Black Hole"}`)
+
+	if rec := postSong(handler, `{"group": "Muse", "song": "Supermassive Black Hole"}`); rec.Code != http.StatusConflict {
+		t.Errorf("second POST /song = %d, want %d: %s", rec.Code, http.StatusConflict, rec.Body)
+	}
+}
+
+func TestAddSongUnknownToProviderIsNotFound(t *testing.T) {
+	handler, _ := newTestSongHandler(t)
+
+	if rec := postSong(handler, `{"group": "Muse", "song": "Unknown"}`); rec.Code != http.StatusNotFound {
+		t.Errorf("POST /song = %d, want %d: %s", rec.Code, http.StatusNotFound, rec.Body)
+	}
+}
+
+func TestAddSongRejectedByProviderIsUnprocessable(t *testing.T) {
+	handler, provider := newTestSongHandler(t)
+	provider.FailNext(1, http.StatusBadRequest, "")
+
+	if rec := postSong(handler, `{"group": "Muse", "song": "Supermassive Black Hole"}`); rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("POST /song = %d, want %d: %s", rec.Code, http.StatusUnprocessableEntity, rec.Body)
+	}
+}
+
+func TestAddSongProviderFailureIsBadGateway(t *testing.T) {
+	handler, provider := newTestSongHandler(t)
+	provider.FailNext(1, http.StatusInternalServerError, "")
+
+	if rec := postSong(handler, `{"group": "Muse", "song": "Supermassive Black Hole"}`); rec.Code != http.StatusBadGateway {
+		t.Errorf("POST /song = %d, want %d: %s", rec.Code, http.StatusBadGateway, rec.Body)
+	}
+}
+
+func TestAddSongInvalidBodyIsBadRequest(t *testing.T) {
+	handler, _ := newTestSongHandler(t)
+
+	if rec := postSong(handler, `{"group": "Muse"}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("POST /song without a song = %d, want %d: %s", rec.Code, http.StatusBadRequest, rec.Body)
+	}
+}
+
+// assertBadRequest serves req with handler and checks for a 400 problem response.
+func assertBadRequest(t *testing.T, handler http.HandlerFunc, req *http.Request) {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	handler(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("GET %s = %d, want %d: %s", req.URL, rec.Code, http.StatusBadRequest, rec.Body)
+	}
+	if got := rec.Header().Get("Content-Type"); got != "application/problem+json" {
+		t.Errorf("GET %s Content-Type = %q, want application/problem+json", req.URL, got)
+	}
+}
+
+func TestGetSongsRejectsUnknownSortField(t *testing.T) {
+	handler, _ := newTestSongHandler(t)
+	assertBadRequest(t, handler.GetSongPaginated, httptest.NewRequest(http.MethodGet, "/songs?sort=text", nil))
+}
+
+func TestGetSongsRejectsUnknownMatchMode(t *testing.T) {
+	handler, _ := newTestSongHandler(t)
+	assertBadRequest(t, handler.GetSongPaginated, httptest.NewRequest(http.MethodGet, "/songs?group=Muse&match=prefix", nil))
+}
+
+func TestGetSongsRejectsUnknownField(t *testing.T) {
+	handler, _ := newTestSongHandler(t)
+	assertBadRequest(t, handler.GetSongPaginated, httptest.NewRequest(http.MethodGet, "/songs?fields=genre", nil))
+}
+
+func TestGetSongsRejectsUnknownLanguage(t *testing.T) {
+	handler, _ := newTestSongHandler(t)
+	assertBadRequest(t, handler.GetSongPaginated, httptest.NewRequest(http.MethodGet, "/songs?q=love&lang=klingon", nil))
+}
+
+func TestGetSongsRejectsCursorWithSort(t *testing.T) {
+	handler, _ := newTestSongHandler(t)
+	assertBadRequest(t, handler.GetSongPaginated, httptest.NewRequest(http.MethodGet, "/songs?cursor=abc&sort=id", nil))
+}
+
+func TestGetSongsRejectsPageSizeAboveMax(t *testing.T) {
+	handler, _ := newTestSongHandler(t)
+	assertBadRequest(t, handler.GetSongPaginated, httptest.NewRequest(http.MethodGet, "/songs?pageSize=101", nil))
+}
+
+func TestGetSongsRejectsOverflowingPage(t *testing.T) {
+	handler, _ := newTestSongHandler(t)
+	assertBadRequest(t, handler.GetSongPaginated, httptest.NewRequest(http.MethodGet, "/songs?page=9223372036854775807&pageSize=100", nil))
+}
+
+func TestGetSongHistoryRejectsPageSizeAboveMax(t *testing.T) {
+	handler, _ := newTestSongHandler(t)
+	req := mux.SetURLVars(httptest.NewRequest(http.MethodGet, "/song/song-1/history?pageSize=1000", nil), map[string]string{"id": "song-1"})
+	assertBadRequest(t, handler.GetSongHistoryHandler, req)
+}
+
+func TestGetTrashRejectsOverflowingPage(t *testing.T) {
+	handler, _ := newTestSongHandler(t)
+	assertBadRequest(t, handler.GetTrashHandler, httptest.NewRequest(http.MethodGet, "/trash?page=9223372036854775807", nil))
+}
+
+func TestFuzzySearchRejectsThresholdAboveOne(t *testing.T) {
+	handler, _ := newTestSongHandler(t)
+	assertBadRequest(t, handler.FuzzySearchHandler, httptest.NewRequest(http.MethodGet, "/songs/fuzzy?q=Muse&threshold=2", nil))
+}
+
+func TestFuzzySearchRejectsPageSizeAboveMax(t *testing.T) {
+	handler, _ := newTestSongHandler(t)
+	assertBadRequest(t, handler.FuzzySearchHandler, httptest.NewRequest(http.MethodGet, "/songs/fuzzy?q=Muse&pageSize=500", nil))
+}
+
+func TestGetSongSectionRejectsNumberBeyondInt4(t *testing.T) {
+	handler, _ := newTestSongHandler(t)
+	req := mux.SetURLVars(httptest.NewRequest(http.MethodGet, "/song/song-1/sections/3000000000", nil), map[string]string{"id": "song-1", "number": "3000000000"})
+	assertBadRequest(t, handler.GetSongSectionHandler, req)
+}
+
+func TestGetSongSectionsRejectsReversedRange(t *testing.T) {
+	handler, _ := newTestSongHandler(t)
+	req := mux.SetURLVars(httptest.NewRequest(http.MethodGet, "/song/song-1/sections?from=3&to=2", nil), map[string]string{"id": "song-1"})
+	assertBadRequest(t, handler.GetSongSectionsHandler, req)
+}
+
+func TestGetSongLyricsRejectsOverflowingPage(t *testing.T) {
+	handler, _ := newTestSongHandler(t)
+	req := mux.SetURLVars(httptest.NewRequest(http.MethodGet, "/song/song-1/lyrics?page=9223372036854775807", nil), map[string]string{"id": "song-1"})
+	assertBadRequest(t, handler.GetSongTextPaginatedHandler, req)
+}
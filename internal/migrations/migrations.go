@@ -8,18 +8,25 @@ import (
 	_ "github.com/golang-migrate/migrate/v4/source/file"
 )
 
-func ApplyMigrations(dbHost, dbPort, dbUser, dbPassword, dbName string) error {
+// ApplyMigrations runs all pending migrations and returns the resulting schema version.
+func ApplyMigrations(dbHost, dbPort, dbUser, dbPassword, dbName string) (uint, error) {
 	connString := fmt.Sprintf("postgresql://%s:%s@%s:%s/%s?sslmode=disable", dbUser, dbPassword, dbHost, dbPort, dbName)
 
 	m, err := migrate.New("file://migrations", connString)
 	if err != nil {
-		return err
+		return 0, err
 	}
+	defer m.Close()
 
 	err = m.Up()
 	if err != nil && err != migrate.ErrNoChange {
-		return err
+		return 0, err
 	}
 
-	return nil
+	version, _, err := m.Version()
+	if err != nil {
+		return 0, fmt.Errorf("failed to read schema version: %w", err)
+	}
+
+	return version, nil
 }
@@ -0,0 +1,43 @@
+package models
+
+import (
+	"music-library/internal/apperrors"
+	"strings"
+	"time"
+)
+
+type Album struct {
+	ID          int64     `json:"id"`
+	Title       string    `json:"title"`
+	ArtistID    int64     `json:"artist_id"`
+	ArtistName  string    `json:"artist"`
+	ReleaseDate string    `json:"release_date,omitempty"`
+	CoverLink   string    `json:"cover_link"`
+	TrackCount  int       `json:"track_count"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// NewAlbum validates album details. releaseDate is empty or an ISO 8601 date.
+func NewAlbum(title, artist, releaseDate, coverLink string) (*Album, error) {
+	title = strings.TrimSpace(title)
+	artist = CleanArtistName(artist)
+	if title == "" || artist == "" {
+		return nil, apperrors.New(apperrors.ErrValidation, "album title and artist cannot be empty")
+	}
+	if len(title) > 255 || len(artist) > 255 || len(coverLink) > 255 {
+		return nil, apperrors.New(apperrors.ErrValidation, "album title, artist and cover link cannot be longer than 255 characters")
+	}
+	if releaseDate != "" {
+		if _, err := time.Parse("2006-01-02", releaseDate); err != nil {
+			return nil, apperrors.New(apperrors.ErrValidation, "release date must be formatted as YYYY-MM-DD")
+		}
+	}
+
+	return &Album{
+		Title:       title,
+		ArtistName:  artist,
+		ReleaseDate: releaseDate,
+		CoverLink:   coverLink,
+	}, nil
+}
@@ -0,0 +1,34 @@
+package models
+
+import (
+	"music-library/internal/apperrors"
+	"music-library/internal/auth"
+	"strings"
+	"time"
+)
+
+// APIKey describes an issued API key. Only its hash is stored; Key is set once, when the key is issued.
+type APIKey struct {
+	ID        int64      `json:"id"`
+	Name      string     `json:"name"`
+	Role      auth.Role  `json:"role"`
+	Prefix    string     `json:"prefix"`
+	CreatedAt time.Time  `json:"created_at"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+	Key       string     `json:"key,omitempty"`
+}
+
+func NewAPIKey(name string, role auth.Role) (*APIKey, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, apperrors.New(apperrors.ErrValidation, "key name cannot be empty")
+	}
+	if len(name) > 255 {
+		return nil, apperrors.New(apperrors.ErrValidation, "key name cannot be longer than 255 characters")
+	}
+	if !role.Valid() {
+		return nil, apperrors.New(apperrors.ErrValidation, "role must be one of reader, editor or admin")
+	}
+
+	return &APIKey{Name: name, Role: role}, nil
+}
@@ -0,0 +1,66 @@
+package models
+
+import (
+	"music-library/internal/apperrors"
+	"strings"
+	"time"
+)
+
+// ArtistRole is the way an artist is credited on a song.
+type ArtistRole string
+
+const (
+	RolePrimary  ArtistRole = "primary"
+	RoleFeatured ArtistRole = "featured"
+	RoleComposer ArtistRole = "composer"
+	RoleProducer ArtistRole = "producer"
+)
+
+// Valid reports whether r is a known role.
+func (r ArtistRole) Valid() bool {
+	switch r {
+	case RolePrimary, RoleFeatured, RoleComposer, RoleProducer:
+		return true
+	}
+	return false
+}
+
+type Artist struct {
+	ID        int64     `json:"id"`
+	Name      string    `json:"name"`
+	SongCount int       `json:"song_count"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// SongCredit links an artist to a song in a role.
+type SongCredit struct {
+	SongID     string     `json:"song_id"`
+	ArtistID   int64      `json:"artist_id"`
+	ArtistName string     `json:"artist_name"`
+	Role       ArtistRole `json:"role"`
+	Position   int        `json:"position"`
+}
+
+func NewArtist(name string) (*Artist, error) {
+	name = CleanArtistName(name)
+	if name == "" {
+		return nil, apperrors.New(apperrors.ErrValidation, "artist name cannot be empty")
+	}
+	if len(name) > 255 {
+		return nil, apperrors.New(apperrors.ErrValidation, "artist name cannot be longer than 255 characters")
+	}
+
+	return &Artist{Name: name}, nil
+}
+
+// CleanArtistName trims an artist name and collapses inner whitespace.
+func CleanArtistName(name string) string {
+	return strings.Join(strings.Fields(name), " ")
+}
+
+// NormalizeArtistName returns the key under which spellings of the same artist are merged,
+// so "Muse", "muse" and "MUSE " are one artist.
+func NormalizeArtistName(name string) string {
+	return strings.ToLower(CleanArtistName(name))
+}
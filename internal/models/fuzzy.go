@@ -0,0 +1,53 @@
+package models
+
+import (
+	"music-library/internal/apperrors"
+	"strings"
+)
+
+// FuzzyQuery is a typo-tolerant search by group and song name. Q matches either name.
+type FuzzyQuery struct {
+	Q     string
+	Group string
+	Song  string
+	// Threshold is the minimum trigram word similarity, between 0 and 1, of a match.
+	Threshold float64
+}
+
+func NewFuzzyQuery(q, group, song string, threshold float64) (*FuzzyQuery, error) {
+	query := &FuzzyQuery{
+		Q:         strings.TrimSpace(q),
+		Group:     strings.TrimSpace(group),
+		Song:      strings.TrimSpace(song),
+		Threshold: threshold,
+	}
+	if query.Q == "" && query.Group == "" && query.Song == "" {
+		return nil, apperrors.New(apperrors.ErrValidation, "q, group or song is required")
+	}
+	if threshold <= 0 || threshold > 1 {
+		return nil, apperrors.New(apperrors.ErrValidation, "threshold must be greater than 0 and at most 1")
+	}
+	return query, nil
+}
+
+// FuzzyResult is a page of fuzzy search results, best match first, with their similarity as rank.
+type FuzzyResult struct {
+	Songs []*Song `json:"songs"`
+	// DidYouMean lists existing names close to the search terms that differ from them.
+	DidYouMean *NameSuggestions `json:"did_you_mean,omitempty"`
+}
+
+// NameSuggestions are the names closest to each search term, closest first.
+type NameSuggestions struct {
+	Q     []string `json:"q,omitempty"`
+	Group []string `json:"group,omitempty"`
+	Song  []string `json:"song,omitempty"`
+}
+
+// SongSuggestion is an autocomplete match.
+type SongSuggestion struct {
+	ID        string  `json:"id"`
+	GroupName string  `json:"group_name"`
+	SongName  string  `json:"song_name"`
+	Score     float64 `json:"score"`
+}
@@ -0,0 +1,22 @@
+package models
+
+// CheckStatus is the outcome of a health check.
+type CheckStatus string
+
+const (
+	CheckOK   CheckStatus = "ok"
+	CheckFail CheckStatus = "fail"
+)
+
+// CheckResult is the outcome of one dependency check.
+type CheckResult struct {
+	Status    CheckStatus `json:"status"`
+	LatencyMS float64     `json:"latency_ms"`
+	Error     string      `json:"error,omitempty"`
+}
+
+// Readiness is the readiness report of the service.
+type Readiness struct {
+	Status CheckStatus            `json:"status"`
+	Checks map[string]CheckResult `json:"checks"`
+}
@@ -0,0 +1,18 @@
+package models
+
+import (
+	"crypto/rand"
+	"fmt"
+)
+
+// newID returns prefix followed by a random (version 4) UUID, so IDs generated at the same
+// moment by concurrent workers do not collide.
+func newID(prefix string) string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(fmt.Sprintf("failed to read random bytes: %v", err))
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%s-%x-%x-%x-%x-%x", prefix, b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
@@ -0,0 +1,39 @@
+package models
+
+// ImportRowStatus is the outcome of a single row of a bulk import.
+type ImportRowStatus string
+
+const (
+	ImportCreated   ImportRowStatus = "created"
+	ImportDuplicate ImportRowStatus = "duplicate"
+	ImportFailed    ImportRowStatus = "failed"
+	// ImportValid marks a row that passed validation in a dry run.
+	ImportValid ImportRowStatus = "valid"
+)
+
+// ImportRow is the result of importing one input row.
+type ImportRow struct {
+	Row       int             `json:"row"`
+	GroupName string          `json:"group_name"`
+	SongName  string          `json:"song_name"`
+	Status    ImportRowStatus `json:"status"`
+	SongID    string          `json:"song_id,omitempty"`
+	Error     string          `json:"error,omitempty"`
+}
+
+// ImportReport summarizes a bulk import. Aborted is set when the input could not be read
+// to the end or the import was cancelled: the rows up to LastLine were processed, those
+// added stay added, and Error says why it stopped.
+type ImportReport struct {
+	DryRun    bool        `json:"dry_run"`
+	Total     int         `json:"total"`
+	Created   int         `json:"created"`
+	Duplicate int         `json:"duplicate"`
+	Failed    int         `json:"failed"`
+	Valid     int         `json:"valid"`
+	Truncated bool        `json:"truncated"`
+	Aborted   bool        `json:"aborted"`
+	Error     string      `json:"error,omitempty"`
+	LastLine  int         `json:"last_line"`
+	Rows      []ImportRow `json:"rows"`
+}
@@ -0,0 +1,50 @@
+package models
+
+import (
+	"music-library/internal/apperrors"
+	"music-library/internal/auth"
+	"time"
+)
+
+// JobStatus is the lifecycle state of a background song job.
+type JobStatus string
+
+const (
+	JobPending   JobStatus = "pending"
+	JobRunning   JobStatus = "running"
+	JobSucceeded JobStatus = "succeeded"
+	JobFailed    JobStatus = "failed"
+)
+
+// Job is a queued request to enrich and store a song.
+type Job struct {
+	ID        string    `json:"id"`
+	GroupName string    `json:"group_name"`
+	SongName  string    `json:"song_name"`
+	Status    JobStatus `json:"status"`
+	Attempts  int       `json:"attempts"`
+	LastError string    `json:"last_error,omitempty"`
+	SongID    string    `json:"song_id,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	// Actor and ActorRole are the subject and role of the credential that queued the job,
+	// empty when it was queued without one. The song is added on their behalf.
+	Actor     string    `json:"-"`
+	ActorRole auth.Role `json:"-"`
+}
+
+func NewJob(groupName, songName string) (*Job, error) {
+	if groupName == "" || songName == "" {
+		return nil, apperrors.New(apperrors.ErrValidation, "group name and song name cannot be empty")
+	}
+
+	now := time.Now().UTC()
+	return &Job{
+		ID:        newID("job"),
+		GroupName: groupName,
+		SongName:  songName,
+		Status:    JobPending,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}, nil
+}
@@ -0,0 +1,99 @@
+package models
+
+import (
+	"regexp"
+	"strings"
+)
+
+// SectionKind is the part of a song a lyrics section plays.
+type SectionKind string
+
+const (
+	SectionVerse  SectionKind = "verse"
+	SectionChorus SectionKind = "chorus"
+	SectionBridge SectionKind = "bridge"
+)
+
+// LyricsSection is a part of a song's lyrics, such as a verse or the chorus.
+type LyricsSection struct {
+	// Number is the 1-based position of the section in the song.
+	Number int         `json:"number"`
+	Kind   SectionKind `json:"kind"`
+	Lines  []string    `json:"lines"`
+}
+
+// sectionHeader matches a line naming the section that follows, such as "[Chorus]" or "Verse 2:".
+var sectionHeader = regexp.MustCompile(`(?i)^(?:\[\s*(verse|chorus|refrain|bridge)\b[^\]]*\]|(verse|chorus|refrain|bridge)(?:\s+\d+)?\s*:?)$`)
+
+// ParseLyrics splits lyrics into sections at blank lines. A section starting with a header line
+// such as "[Chorus]" or "Bridge:" takes its kind from it, and the header is dropped; a lone header
+// repeats the lines of the last section of that kind. Without a header, a section whose lines
+// appear more than once is a chorus and any other a verse.
+func ParseLyrics(text string) []LyricsSection {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+
+	var (
+		blocks  [][]string
+		current []string
+	)
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimRight(line, " \t")
+		if strings.TrimSpace(line) == "" {
+			if len(current) > 0 {
+				blocks = append(blocks, current)
+				current = nil
+			}
+			continue
+		}
+		current = append(current, line)
+	}
+	if len(current) > 0 {
+		blocks = append(blocks, current)
+	}
+
+	seen := map[string]int{}
+	for _, block := range blocks {
+		seen[blockKey(block)]++
+	}
+
+	sections := make([]LyricsSection, 0, len(blocks))
+	last := map[SectionKind][]string{}
+	for _, block := range blocks {
+		section := LyricsSection{Number: len(sections) + 1, Kind: SectionVerse, Lines: block}
+		if m := sectionHeader.FindStringSubmatch(strings.TrimSpace(block[0])); m != nil {
+			section.Kind = sectionKind(m[1] + m[2])
+			section.Lines = block[1:]
+			if len(section.Lines) == 0 {
+				section.Lines = last[section.Kind]
+			}
+		} else if seen[blockKey(block)] > 1 {
+			section.Kind = SectionChorus
+		}
+		if section.Lines == nil {
+			section.Lines = []string{}
+		}
+
+		last[section.Kind] = section.Lines
+		sections = append(sections, section)
+	}
+	return sections
+}
+
+// blockKey identifies the lines of a block regardless of case and spacing.
+func blockKey(block []string) string {
+	lines := make([]string, len(block))
+	for i, line := range block {
+		lines[i] = strings.ToLower(strings.Join(strings.Fields(line), " "))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func sectionKind(name string) SectionKind {
+	switch strings.ToLower(name) {
+	case "chorus", "refrain":
+		return SectionChorus
+	case "bridge":
+		return SectionBridge
+	}
+	return SectionVerse
+}
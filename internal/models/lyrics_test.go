@@ -0,0 +1,61 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseLyricsSplitsAtBlankLines(t *testing.T) {
+	got := ParseLyrics("one \r\ntwo\n\n\nthree")
+	want := []LyricsSection{
+		{Number: 1, Kind: SectionVerse, Lines: []string{"one", "two"}},
+		{Number: 2, Kind: SectionVerse, Lines: []string{"three"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseLyrics() = %#v, want %#v", got, want)
+	}
+}
+
+func TestParseLyricsEmpty(t *testing.T) {
+	if got := ParseLyrics("\n  \n\t\n"); got == nil || len(got) != 0 {
+		t.Errorf("ParseLyrics() of blank lines = %#v, want an empty list", got)
+	}
+}
+
+func TestParseLyricsRepeatedBlockIsChorus(t *testing.T) {
+	got := ParseLyrics("first\n\nla la\nLa  la\n\nother\n\nla la\nla la")
+	for _, i := range []int{1, 3} {
+		if got[i].Kind != SectionChorus {
+			t.Errorf("section %d is a %s, want a chorus", got[i].Number, got[i].Kind)
+		}
+	}
+	if got[0].Kind != SectionVerse || got[2].Kind != SectionVerse {
+		t.Errorf("ParseLyrics() = %#v, want the other sections to be verses", got)
+	}
+}
+
+func TestParseLyricsHeaderNamesKind(t *testing.T) {
+	got := ParseLyrics("[Chorus]\nhey\n\nBridge:\nslow")
+	want := []LyricsSection{
+		{Number: 1, Kind: SectionChorus, Lines: []string{"hey"}},
+		{Number: 2, Kind: SectionBridge, Lines: []string{"slow"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseLyrics() = %#v, want %#v", got, want)
+	}
+}
+
+func TestParseLyricsLoneHeaderRepeatsSection(t *testing.T) {
+	got := ParseLyrics("[Chorus]\nhey\nho\n\nin between\n\n[Chorus]")
+	want := LyricsSection{Number: 3, Kind: SectionChorus, Lines: []string{"hey", "ho"}}
+	if len(got) != 3 || !reflect.DeepEqual(got[2], want) {
+		t.Errorf("ParseLyrics() = %#v, want the chorus repeated as section 3", got)
+	}
+}
+
+func TestParseLyricsKeepsHeaderWordsInsideLines(t *testing.T) {
+	got := ParseLyrics("the chorus: sing it")
+	if len(got) != 1 || !reflect.DeepEqual(got[0].Lines, []string{"the chorus: sing it"}) {
+		t.Errorf("ParseLyrics() = %#v, want the line kept", got)
+	}
+}
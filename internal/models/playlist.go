@@ -0,0 +1,31 @@
+package models
+
+import (
+	"music-library/internal/apperrors"
+	"strings"
+	"time"
+)
+
+// Playlist is an ordered list of songs. Version changes with every modification and
+// is used to detect concurrent edits.
+type Playlist struct {
+	ID        int64     `json:"id"`
+	Name      string    `json:"name"`
+	Version   int       `json:"version"`
+	SongCount int       `json:"song_count"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Songs     []*Song   `json:"songs,omitempty"`
+}
+
+func NewPlaylist(name string) (*Playlist, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, apperrors.New(apperrors.ErrValidation, "playlist name cannot be empty")
+	}
+	if len(name) > 255 {
+		return nil, apperrors.New(apperrors.ErrValidation, "playlist name cannot be longer than 255 characters")
+	}
+
+	return &Playlist{Name: name}, nil
+}
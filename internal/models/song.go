@@ -1,7 +1,7 @@
 package models
 
 import (
-	"fmt"
+	"music-library/internal/apperrors"
 	"time"
 )
 
@@ -10,13 +10,37 @@ type Song struct {
 	GroupName   string `json:"group_name"`
 	SongName    string `json:"song_name"`
 	ReleaseDate string `json:"release_date"`
+	// Text holds the lyrics as written. Their sections are not part of the song: they are derived
+	// from Text, stored apart and served by the lyrics and sections routes, so that songs read in
+	// lists do not load them.
 	Text        string `json:"text"`
 	Link        string `json:"link"`
+	AlbumID     *int64 `json:"album_id,omitempty"`
+	DiscNumber  int    `json:"disc_number,omitempty"`
+	TrackNumber int    `json:"track_number,omitempty"`
+	Version     int    `json:"version"`
+	// Language is the text search configuration of the song, such as "english".
+	Language string `json:"language"`
+	// DeletedAt is set while the song is in the trash.
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+
+	// Rank and Highlight are set on search results.
+	Rank      float64        `json:"rank,omitempty"`
+	Highlight *SongHighlight `json:"highlight,omitempty"`
+}
+
+// SongHighlight holds the fields of a search result with the matched terms wrapped in <mark> tags.
+// Text is cut down to the fragments around the matches. The fields are HTML-escaped, so the only
+// markup they contain are the <mark> tags.
+type SongHighlight struct {
+	SongName  string `json:"song_name"`
+	GroupName string `json:"group_name"`
+	Text      string `json:"text"`
 }
 
 func NewSong(groupName, songName, text, link, releaseDate string) (*Song, error) {
 	if groupName == "" || songName == "" {
-		return nil, fmt.Errorf("group name and song name cannot be empty")
+		return nil, apperrors.New(apperrors.ErrValidation, "group name and song name cannot be empty")
 	}
 
 	return &Song{
@@ -30,5 +54,5 @@ func NewSong(groupName, songName, text, link, releaseDate string) (*Song, error)
 }
 
 func generateID() string {
-	return fmt.Sprintf("song-%d", time.Now().UnixNano())
+	return newID("song")
 }
@@ -0,0 +1,63 @@
+package models
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"music-library/internal/apperrors"
+	"time"
+)
+
+// SongCursor marks a position in the song list, which is ordered by release date and ID,
+// newest first. It is handed to clients as an opaque token.
+type SongCursor struct {
+	ReleaseDate string `json:"d"`
+	ID          string `json:"i"`
+	// Backward selects the songs before the position instead of after it.
+	Backward bool `json:"b,omitempty"`
+}
+
+// SongPage is a page of the song list with the number of songs matching the filter and, when paged
+// by cursor, the cursors of its neighbouring pages, if there are any.
+type SongPage struct {
+	Songs []*Song
+	Next  *SongCursor
+	Prev  *SongCursor
+	Total int64
+	// Estimated is set when Total is the query planner's estimate rather than a count.
+	Estimated bool
+}
+
+// CursorAfter returns the cursor of the songs following song.
+func CursorAfter(song *Song) *SongCursor {
+	return &SongCursor{ReleaseDate: song.ReleaseDate, ID: song.ID}
+}
+
+// CursorBefore returns the cursor of the songs preceding song.
+func CursorBefore(song *Song) *SongCursor {
+	return &SongCursor{ReleaseDate: song.ReleaseDate, ID: song.ID, Backward: true}
+}
+
+// Encode returns the cursor as an opaque token.
+func (c *SongCursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// ParseSongCursor decodes a token returned by Encode.
+func ParseSongCursor(token string) (*SongCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, apperrors.New(apperrors.ErrValidation, "invalid cursor")
+	}
+
+	var cursor SongCursor
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.ID == "" {
+		return nil, apperrors.New(apperrors.ErrValidation, "invalid cursor")
+	}
+	if cursor.ReleaseDate != "" {
+		if _, err := time.Parse(time.RFC3339, cursor.ReleaseDate); err != nil {
+			return nil, apperrors.New(apperrors.ErrValidation, "invalid cursor")
+		}
+	}
+	return &cursor, nil
+}
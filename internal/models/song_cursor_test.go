@@ -0,0 +1,45 @@
+package models
+
+import (
+	"encoding/base64"
+	"errors"
+	"music-library/internal/apperrors"
+	"testing"
+)
+
+func TestSongCursorRoundTrip(t *testing.T) {
+	cursor := CursorAfter(&Song{ID: "song-1", ReleaseDate: "2006-07-16T00:00:00Z"})
+
+	got, err := ParseSongCursor(cursor.Encode())
+	if err != nil {
+		t.Fatalf("ParseSongCursor(): %v", err)
+	}
+	if *got != *cursor {
+		t.Errorf("ParseSongCursor(Encode()) = %+v, want %+v", got, cursor)
+	}
+}
+
+func TestSongCursorRoundTripUndatedSong(t *testing.T) {
+	cursor := CursorBefore(&Song{ID: "song-2"})
+
+	got, err := ParseSongCursor(cursor.Encode())
+	if err != nil {
+		t.Fatalf("ParseSongCursor(): %v", err)
+	}
+	if *got != *cursor {
+		t.Errorf("ParseSongCursor(Encode()) = %+v, want %+v", got, cursor)
+	}
+}
+
+func TestParseSongCursorRejectsGarbage(t *testing.T) {
+	if _, err := ParseSongCursor("not a cursor!"); !errors.Is(err, apperrors.ErrValidation) {
+		t.Errorf("ParseSongCursor() = %v, want a validation error", err)
+	}
+}
+
+func TestParseSongCursorRequiresID(t *testing.T) {
+	token := base64.RawURLEncoding.EncodeToString([]byte(`{"d":"2006-07-16T00:00:00Z"}`))
+	if _, err := ParseSongCursor(token); !errors.Is(err, apperrors.ErrValidation) {
+		t.Errorf("ParseSongCursor() without an id = %v, want a validation error", err)
+	}
+}
@@ -0,0 +1,157 @@
+package models
+
+import (
+	"music-library/internal/apperrors"
+	"slices"
+	"strings"
+	"time"
+)
+
+// MatchMode selects how the group and song name filters compare names.
+type MatchMode string
+
+const (
+	// MatchContains finds names containing the value, ignoring case.
+	MatchContains MatchMode = "contains"
+	// MatchExact finds names equal to the value.
+	MatchExact MatchMode = "exact"
+)
+
+// CountMode selects how the total of a song list is computed.
+type CountMode string
+
+const (
+	// CountExact counts the matching songs.
+	CountExact CountMode = "exact"
+	// CountEstimated takes the query planner's estimate, which does not read the songs, for large totals.
+	CountEstimated CountMode = "estimated"
+)
+
+// dateLayout is the format of release dates in filters.
+const dateLayout = "2006-01-02"
+
+// SongSortFields are the fields the song list can be sorted by.
+var SongSortFields = []string{"release_date", "group_name", "song_name", "id"}
+
+// SongFields are the fields a song list response can be cut down to.
+var SongFields = []string{
+	"id", "group_name", "song_name", "release_date", "text", "link", "album_id", "disc_number",
+	"track_number", "version", "language", "deleted_at", "rank", "highlight",
+}
+
+// SongSort is a sort key of the song list.
+type SongSort struct {
+	Field string
+	Desc  bool
+}
+
+// SongFilter selects, orders and shapes the songs of a list.
+type SongFilter struct {
+	// Groups and Songs match songs with any of the names; both must match when both are set.
+	Groups []string
+	Songs  []string
+	Match  MatchMode
+	// ReleasedAfter and ReleasedBefore bound the release date, inclusively, as YYYY-MM-DD.
+	// Undated songs are left out when either is set.
+	ReleasedAfter  string
+	ReleasedBefore string
+	// Q is a full-text search in web search syntax, in Lang only when set.
+	Q    string
+	Lang string
+	// Languages are the text search configurations Q is searched in, resolved by the service.
+	Languages []string
+	// Sort replaces the default order: newest first, or best match first when searching.
+	Sort []SongSort
+	// Fields are the song fields returned, all of them when empty. The id is always returned.
+	Fields []string
+	// Count selects how the total of the list is computed.
+	Count CountMode
+}
+
+// Validate checks the filter and fills in the default match and count modes.
+func (f *SongFilter) Validate() error {
+	switch f.Match {
+	case "":
+		f.Match = MatchContains
+	case MatchContains, MatchExact:
+	default:
+		return apperrors.New(apperrors.ErrValidation, "match must be %s or %s", MatchContains, MatchExact)
+	}
+
+	switch f.Count {
+	case "":
+		f.Count = CountExact
+	case CountExact, CountEstimated:
+	default:
+		return apperrors.New(apperrors.ErrValidation, "count must be %s or %s", CountExact, CountEstimated)
+	}
+
+	var after, before time.Time
+	for _, d := range []struct {
+		name, value string
+		date        *time.Time
+	}{
+		{"released_after", f.ReleasedAfter, &after},
+		{"released_before", f.ReleasedBefore, &before},
+	} {
+		if d.value == "" {
+			continue
+		}
+		date, err := time.Parse(dateLayout, d.value)
+		if err != nil {
+			return apperrors.New(apperrors.ErrValidation, "%s must be a date such as 2006-01-02", d.name)
+		}
+		*d.date = date
+	}
+	if !after.IsZero() && !before.IsZero() && after.After(before) {
+		return apperrors.New(apperrors.ErrValidation, "released_after must not be later than released_before")
+	}
+
+	return nil
+}
+
+// Wants reports whether the field is part of the response.
+func (f *SongFilter) Wants(field string) bool {
+	return len(f.Fields) == 0 || field == "id" || slices.Contains(f.Fields, field)
+}
+
+// ParseSongSort parses a comma-separated list of sort keys such as "group_name,release_date:desc".
+// Keys sort ascending unless followed by ":desc".
+func ParseSongSort(value string) ([]SongSort, error) {
+	var keys []SongSort
+	for _, key := range strings.Split(value, ",") {
+		field, direction, _ := strings.Cut(strings.TrimSpace(key), ":")
+		if !slices.Contains(SongSortFields, field) {
+			return nil, apperrors.New(apperrors.ErrValidation, "cannot sort by %q, use %s", field, strings.Join(SongSortFields, ", "))
+		}
+		if slices.ContainsFunc(keys, func(s SongSort) bool { return s.Field == field }) {
+			return nil, apperrors.New(apperrors.ErrValidation, "sort lists %s twice", field)
+		}
+
+		sort := SongSort{Field: field}
+		switch direction {
+		case "", "asc":
+		case "desc":
+			sort.Desc = true
+		default:
+			return nil, apperrors.New(apperrors.ErrValidation, "sort direction of %s must be asc or desc", field)
+		}
+		keys = append(keys, sort)
+	}
+	return keys, nil
+}
+
+// ParseSongFields parses a comma-separated list of song fields such as "id,song_name".
+func ParseSongFields(value string) ([]string, error) {
+	var fields []string
+	for _, field := range strings.Split(value, ",") {
+		field = strings.TrimSpace(field)
+		if !slices.Contains(SongFields, field) {
+			return nil, apperrors.New(apperrors.ErrValidation, "unknown field %q, use %s", field, strings.Join(SongFields, ", "))
+		}
+		if !slices.Contains(fields, field) {
+			fields = append(fields, field)
+		}
+	}
+	return fields, nil
+}
@@ -0,0 +1,62 @@
+package models
+
+import (
+	"errors"
+	"music-library/internal/apperrors"
+	"reflect"
+	"testing"
+)
+
+func TestSongFilterValidateSetsDefaults(t *testing.T) {
+	var filter SongFilter
+	if err := filter.Validate(); err != nil {
+		t.Fatalf("Validate(): %v", err)
+	}
+	if filter.Match != MatchContains || filter.Count != CountExact {
+		t.Errorf("Validate() set match %q and count %q, want %q and %q", filter.Match, filter.Count, MatchContains, CountExact)
+	}
+}
+
+func TestSongFilterValidateRejectsUnknownMatch(t *testing.T) {
+	filter := SongFilter{Match: "prefix"}
+	if err := filter.Validate(); !errors.Is(err, apperrors.ErrValidation) {
+		t.Errorf("Validate() = %v, want a validation error", err)
+	}
+}
+
+func TestSongFilterValidateRejectsReversedRange(t *testing.T) {
+	filter := SongFilter{ReleasedAfter: "2001-01-01", ReleasedBefore: "2000-12-31"}
+	if err := filter.Validate(); !errors.Is(err, apperrors.ErrValidation) {
+		t.Errorf("Validate() = %v, want a validation error", err)
+	}
+}
+
+func TestSongFilterValidateAcceptsSingleDay(t *testing.T) {
+	filter := SongFilter{ReleasedAfter: "2000-01-01", ReleasedBefore: "2000-01-01"}
+	if err := filter.Validate(); err != nil {
+		t.Errorf("Validate() = %v, want a one day range to pass", err)
+	}
+}
+
+func TestParseSongSort(t *testing.T) {
+	got, err := ParseSongSort("group_name, release_date:desc")
+	if err != nil {
+		t.Fatalf("ParseSongSort(): %v", err)
+	}
+	want := []SongSort{{Field: "group_name"}, {Field: "release_date", Desc: true}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseSongSort() = %+v, want %+v", got, want)
+	}
+}
+
+func TestParseSongSortRejectsUnsortableField(t *testing.T) {
+	if _, err := ParseSongSort("text"); !errors.Is(err, apperrors.ErrValidation) {
+		t.Errorf("ParseSongSort(\"text\") = %v, want a validation error", err)
+	}
+}
+
+func TestParseSongSortRejectsRepeatedField(t *testing.T) {
+	if _, err := ParseSongSort("id,id:desc"); !errors.Is(err, apperrors.ErrValidation) {
+		t.Errorf("ParseSongSort() of a repeated field = %v, want a validation error", err)
+	}
+}
@@ -0,0 +1,109 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"music-library/internal/apperrors"
+	"time"
+)
+
+// SongPatch is a JSON merge patch (RFC 7396) of a song. Nil fields are left unchanged;
+// a field set to null in the patch is cleared, which is stored as an empty value.
+type SongPatch struct {
+	GroupName   *string
+	SongName    *string
+	ReleaseDate *string
+	Text        *string
+	Link        *string
+	Language    *string
+}
+
+// readOnlySongFields are song fields that a patch may repeat but not change.
+var readOnlySongFields = map[string]bool{
+	"id":           true,
+	"album_id":     true,
+	"disc_number":  true,
+	"track_number": true,
+	"version":      true,
+	"deleted_at":   true,
+}
+
+// ParseSongPatch parses and validates a merge patch document. Only the fields present in the patch are validated.
+func ParseSongPatch(data []byte) (*SongPatch, error) {
+	if !bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+		return nil, apperrors.New(apperrors.ErrValidation, "merge patch must be a JSON object")
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, apperrors.Wrap(apperrors.ErrValidation, err, "merge patch is not valid JSON")
+	}
+
+	patch := &SongPatch{}
+	targets := map[string]**string{
+		"group_name":   &patch.GroupName,
+		"song_name":    &patch.SongName,
+		"release_date": &patch.ReleaseDate,
+		"text":         &patch.Text,
+		"link":         &patch.Link,
+		"language":     &patch.Language,
+	}
+
+	for name, raw := range fields {
+		if readOnlySongFields[name] {
+			return nil, apperrors.New(apperrors.ErrValidation, "field %q cannot be patched", name)
+		}
+		target, ok := targets[name]
+		if !ok {
+			return nil, apperrors.New(apperrors.ErrValidation, "unknown field %q", name)
+		}
+
+		value := ""
+		if !bytes.Equal(bytes.TrimSpace(raw), []byte("null")) {
+			if err := json.Unmarshal(raw, &value); err != nil {
+				return nil, apperrors.New(apperrors.ErrValidation, "field %q must be a string or null", name)
+			}
+		}
+		*target = &value
+	}
+
+	if err := patch.validate(); err != nil {
+		return nil, err
+	}
+	return patch, nil
+}
+
+func (p *SongPatch) validate() error {
+	if p.GroupName != nil && *p.GroupName == "" {
+		return apperrors.New(apperrors.ErrValidation, "group name cannot be empty")
+	}
+	if p.SongName != nil && *p.SongName == "" {
+		return apperrors.New(apperrors.ErrValidation, "song name cannot be empty")
+	}
+	if p.ReleaseDate != nil && *p.ReleaseDate != "" {
+		if _, err := time.Parse("2006-01-02", *p.ReleaseDate); err != nil {
+			return apperrors.New(apperrors.ErrValidation, "release date must be formatted as YYYY-MM-DD")
+		}
+	}
+	return nil
+}
+
+// Apply returns a copy of song with the patch applied.
+func (p *SongPatch) Apply(song Song) Song {
+	for _, f := range []struct {
+		patch *string
+		field *string
+	}{
+		{p.GroupName, &song.GroupName},
+		{p.SongName, &song.SongName},
+		{p.ReleaseDate, &song.ReleaseDate},
+		{p.Text, &song.Text},
+		{p.Link, &song.Link},
+		{p.Language, &song.Language},
+	} {
+		if f.patch != nil {
+			*f.field = *f.patch
+		}
+	}
+	return song
+}
@@ -0,0 +1,68 @@
+package models
+
+import (
+	"errors"
+	"music-library/internal/apperrors"
+	"testing"
+)
+
+var patchedSong = Song{
+	ID:          "song-1",
+	GroupName:   "Muse",
+	SongName:    "Uprising",
+	ReleaseDate: "2009-09-07",
+	Text:        "Paranoia is in bloom",
+	Link:        "https://example.com",
+	Language:    "english",
+	Version:     3,
+}
+
+func TestSongPatchChangesOnlyPresentFields(t *testing.T) {
+	patch, err := ParseSongPatch([]byte(`{"song_name": "Resistance", "link": "https://example.org"}`))
+	if err != nil {
+		t.Fatalf("ParseSongPatch(): %v", err)
+	}
+
+	want := patchedSong
+	want.SongName, want.Link = "Resistance", "https://example.org"
+	if got := patch.Apply(patchedSong); got != want {
+		t.Errorf("Apply() = %+v, want %+v", got, want)
+	}
+}
+
+func TestSongPatchNullClearsField(t *testing.T) {
+	patch, err := ParseSongPatch([]byte(`{"text": null, "release_date": null}`))
+	if err != nil {
+		t.Fatalf("ParseSongPatch(): %v", err)
+	}
+
+	want := patchedSong
+	want.Text, want.ReleaseDate = "", ""
+	if got := patch.Apply(patchedSong); got != want {
+		t.Errorf("Apply() = %+v, want %+v", got, want)
+	}
+}
+
+func TestParseSongPatchRejectsReadOnlyField(t *testing.T) {
+	if _, err := ParseSongPatch([]byte(`{"id": "song-2"}`)); !errors.Is(err, apperrors.ErrValidation) {
+		t.Errorf("ParseSongPatch() of an id = %v, want a validation error", err)
+	}
+}
+
+func TestParseSongPatchRejectsUnknownField(t *testing.T) {
+	if _, err := ParseSongPatch([]byte(`{"genre": "rock"}`)); !errors.Is(err, apperrors.ErrValidation) {
+		t.Errorf("ParseSongPatch() of an unknown field = %v, want a validation error", err)
+	}
+}
+
+func TestParseSongPatchRejectsClearingRequiredField(t *testing.T) {
+	if _, err := ParseSongPatch([]byte(`{"song_name": null}`)); !errors.Is(err, apperrors.ErrValidation) {
+		t.Errorf("ParseSongPatch() clearing song_name = %v, want a validation error", err)
+	}
+}
+
+func TestParseSongPatchRejectsInvalidReleaseDate(t *testing.T) {
+	if _, err := ParseSongPatch([]byte(`{"release_date": "07.09.2009"}`)); !errors.Is(err, apperrors.ErrValidation) {
+		t.Errorf("ParseSongPatch() of a non-ISO date = %v, want a validation error", err)
+	}
+}
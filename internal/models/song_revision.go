@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// RevisionAction is the kind of change recorded by a song revision.
+type RevisionAction string
+
+const (
+	RevisionInsert RevisionAction = "insert"
+	RevisionUpdate RevisionAction = "update"
+	RevisionDelete RevisionAction = "delete"
+	RevisionRevert RevisionAction = "revert"
+	// RevisionRestore takes a song out of the trash.
+	RevisionRestore RevisionAction = "restore"
+	// RevisionPurge removes a song from the trash for good.
+	RevisionPurge RevisionAction = "purge"
+)
+
+// SongRevision is an entry of a song's audit log. Before is nil for inserts and After for deletes and purges.
+type SongRevision struct {
+	SongID   string         `json:"song_id"`
+	Revision int            `json:"revision"`
+	Action   RevisionAction `json:"action"`
+	// Actor is the subject of the credential that made the change, or "system".
+	Actor string `json:"actor"`
+	// SourceRevision is the revision a revert restored.
+	SourceRevision *int      `json:"source_revision,omitempty"`
+	Before         *Song     `json:"before"`
+	After          *Song     `json:"after"`
+	CreatedAt      time.Time `json:"created_at"`
+}
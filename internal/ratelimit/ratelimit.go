@@ -0,0 +1,174 @@
+// Package ratelimit implements per-client token buckets.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Anonymous is the policy name used for requests without an authenticated role.
+const Anonymous = "anonymous"
+
+// Policy is a token bucket refilled at Rate tokens per second and holding at most Burst tokens.
+// A zero Rate disables limiting.
+type Policy struct {
+	Rate  float64
+	Burst int
+}
+
+// Decision is the outcome of a request against its bucket.
+type Decision struct {
+	Allowed bool
+	// Limit is the bucket size; zero when the request is not limited.
+	Limit     int
+	Remaining int
+	// Reset is the time until the bucket is full again.
+	Reset time.Duration
+	// RetryAfter is the time until the next request is allowed, when this one was not.
+	RetryAfter time.Duration
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// Limiter keeps one token bucket per client, with the policy of the client's role.
+type Limiter struct {
+	policies map[string]Policy
+	now      func() time.Time
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+// sweepInterval is how often buckets that have refilled completely are dropped.
+const sweepInterval = time.Minute
+
+// NewLimiter creates a limiter with a policy per role name. Roles without a policy are not limited.
+func NewLimiter(policies map[string]Policy) *Limiter {
+	return &Limiter{
+		policies: policies,
+		now:      time.Now,
+		buckets:  make(map[string]*bucket),
+	}
+}
+
+// Allow takes a token from the bucket of client under the policy of role.
+func (l *Limiter) Allow(role, client string) Decision {
+	policy, ok := l.policies[role]
+	if !ok || policy.Rate <= 0 || policy.Burst < 1 {
+		return Decision{Allowed: true}
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	key := role + "\x00" + client
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(policy.Burst), last: now}
+		l.buckets[key] = b
+	}
+	b.refill(policy, now)
+
+	d := Decision{Limit: policy.Burst}
+	if b.tokens >= 1 {
+		b.tokens--
+		d.Allowed = true
+	} else {
+		d.RetryAfter = secondsToDuration((1 - b.tokens) / policy.Rate)
+	}
+	d.Remaining = int(math.Floor(b.tokens))
+	d.Reset = secondsToDuration((float64(policy.Burst) - b.tokens) / policy.Rate)
+
+	return d
+}
+
+// Wait takes a token from the bucket of client under the policy of role, waiting for the
+// bucket to refill when it is empty. It gives up when ctx is done.
+func (l *Limiter) Wait(ctx context.Context, role, client string) error {
+	for {
+		d := l.Allow(role, client)
+		if d.Allowed {
+			return nil
+		}
+
+		timer := time.NewTimer(d.RetryAfter)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+func (b *bucket) refill(policy Policy, now time.Time) {
+	elapsed := now.Sub(b.last).Seconds()
+	if elapsed > 0 {
+		b.tokens = math.Min(float64(policy.Burst), b.tokens+elapsed*policy.Rate)
+		b.last = now
+	}
+}
+
+// sweep drops the buckets that would be full by now; a new bucket starts full, so nothing is lost.
+func (l *Limiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < sweepInterval {
+		return
+	}
+	l.lastSweep = now
+
+	for key, b := range l.buckets {
+		role, _, _ := strings.Cut(key, "\x00")
+		policy := l.policies[role]
+		if b.tokens+now.Sub(b.last).Seconds()*policy.Rate >= float64(policy.Burst) {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+func secondsToDuration(s float64) time.Duration {
+	return time.Duration(math.Ceil(s * float64(time.Second)))
+}
+
+// ParsePolicies parses a comma-separated list of role=rate:burst entries such as
+// "anonymous=1:5,reader=10:20", where rate is in requests per second. The rate must be
+// positive; a role left out of the list is not limited.
+func ParsePolicies(spec string) (map[string]Policy, error) {
+	policies := make(map[string]Policy)
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		role, limits, ok := strings.Cut(entry, "=")
+		rateValue, burstValue, ok2 := strings.Cut(limits, ":")
+		if !ok || !ok2 || strings.TrimSpace(role) == "" {
+			return nil, fmt.Errorf("rate limit %q is not of the form role=rate:burst", entry)
+		}
+
+		rate, err := strconv.ParseFloat(strings.TrimSpace(rateValue), 64)
+		if err != nil || rate <= 0 || math.IsInf(rate, 0) || math.IsNaN(rate) {
+			return nil, fmt.Errorf("rate limit %q has an invalid rate, it must be positive", entry)
+		}
+		burst, err := strconv.Atoi(strings.TrimSpace(burstValue))
+		if err != nil || burst < 1 {
+			return nil, fmt.Errorf("rate limit %q has an invalid burst", entry)
+		}
+
+		policies[strings.TrimSpace(role)] = Policy{Rate: rate, Burst: burst}
+	}
+
+	return policies, nil
+}
@@ -0,0 +1,119 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// fakeClock is a controllable time source for limiters.
+type fakeClock struct{ t time.Time }
+
+func (c *fakeClock) now() time.Time          { return c.t }
+func (c *fakeClock) advance(d time.Duration) { c.t = c.t.Add(d) }
+
+// newTestLimiter returns a limiter allowing readers one request per second in bursts of two.
+func newTestLimiter() (*Limiter, *fakeClock) {
+	clock := &fakeClock{t: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	l := NewLimiter(map[string]Policy{"reader": {Rate: 1, Burst: 2}})
+	l.now = clock.now
+	return l, clock
+}
+
+func TestAllowDeniesAfterBurst(t *testing.T) {
+	l, _ := newTestLimiter()
+	l.Allow("reader", "a")
+	l.Allow("reader", "a")
+
+	d := l.Allow("reader", "a")
+	if d.Allowed || d.RetryAfter != time.Second {
+		t.Errorf("Allow() after the burst = %+v, want denied with retry after 1s", d)
+	}
+}
+
+func TestAllowRefillsOverTime(t *testing.T) {
+	l, clock := newTestLimiter()
+	l.Allow("reader", "a")
+	l.Allow("reader", "a")
+
+	clock.advance(time.Second)
+	if d := l.Allow("reader", "a"); !d.Allowed || d.Remaining != 0 {
+		t.Errorf("Allow() a second later = %+v, want allowed with 0 remaining", d)
+	}
+}
+
+func TestAllowRefillStopsAtBurst(t *testing.T) {
+	l, clock := newTestLimiter()
+	l.Allow("reader", "a")
+
+	clock.advance(time.Hour)
+	if d := l.Allow("reader", "a"); d.Remaining != 1 {
+		t.Errorf("Allow() an hour later = %+v, want 1 remaining", d)
+	}
+}
+
+func TestAllowKeepsClientsApart(t *testing.T) {
+	l, _ := newTestLimiter()
+	l.Allow("reader", "a")
+	l.Allow("reader", "a")
+
+	if d := l.Allow("reader", "b"); !d.Allowed {
+		t.Errorf("Allow() for another client = %+v, want allowed", d)
+	}
+}
+
+func TestAllowWithoutPolicy(t *testing.T) {
+	l, _ := newTestLimiter()
+	for i := 0; i < 10; i++ {
+		if d := l.Allow("admin", "a"); !d.Allowed {
+			t.Fatalf("Allow() for a role without a policy = %+v, want allowed", d)
+		}
+	}
+}
+
+func TestWaitForRefill(t *testing.T) {
+	l := NewLimiter(map[string]Policy{"reader": {Rate: 100, Burst: 1}})
+	l.Allow("reader", "a")
+
+	if err := l.Wait(context.Background(), "reader", "a"); err != nil {
+		t.Errorf("Wait() = %v, want a token after the refill", err)
+	}
+}
+
+func TestWaitStopsOnCancel(t *testing.T) {
+	l := NewLimiter(map[string]Policy{"reader": {Rate: 0.001, Burst: 1}})
+	l.Allow("reader", "a")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := l.Wait(ctx, "reader", "a"); !errors.Is(err, context.Canceled) {
+		t.Errorf("Wait() with a cancelled context = %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestParsePolicies(t *testing.T) {
+	got, err := ParsePolicies(" anonymous = 0.2 : 5 , reader=10:20,")
+	if err != nil {
+		t.Fatalf("ParsePolicies(): %v", err)
+	}
+	if len(got) != 2 || got["anonymous"] != (Policy{Rate: 0.2, Burst: 5}) || got["reader"] != (Policy{Rate: 10, Burst: 20}) {
+		t.Errorf("ParsePolicies() = %v", got)
+	}
+}
+
+func TestParsePoliciesRejectsZeroRate(t *testing.T) {
+	for _, spec := range []string{"reader=0:5", "reader=-1:5", "reader=NaN:5"} {
+		if _, err := ParsePolicies(spec); err == nil {
+			t.Errorf("ParsePolicies(%q) accepted a rate that never refills", spec)
+		}
+	}
+}
+
+func TestParsePoliciesRejectsMalformedEntry(t *testing.T) {
+	for _, spec := range []string{"reader=1", "reader=1:0", "=1:5"} {
+		if _, err := ParsePolicies(spec); err == nil {
+			t.Errorf("ParsePolicies(%q) = nil error, want one", spec)
+		}
+	}
+}
@@ -0,0 +1,334 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"music-library/internal/apperrors"
+	"music-library/internal/metrics"
+	"music-library/internal/models"
+	"time"
+)
+
+type AlbumRepository struct {
+	db *sql.DB
+}
+
+func NewAlbumRepository(db *sql.DB) *AlbumRepository {
+	return &AlbumRepository{
+		db: db,
+	}
+}
+
+// albumSelect selects albums with their artist name and number of tracks, in the order read by scanAlbum.
+const albumSelect = `SELECT al.id, al.title, al.artist_id, ar.name, al.release_date, al.cover_link,
+	(SELECT count(*) FROM songs s WHERE s.album_id = al.id AND s.deleted_at IS NULL), al.created_at, al.updated_at
+	FROM albums al JOIN artists ar ON ar.id = al.artist_id`
+
+func scanAlbum(row interface{ Scan(...interface{}) error }) (*models.Album, error) {
+	var (
+		album       models.Album
+		releaseDate sql.NullString
+	)
+	if err := row.Scan(&album.ID, &album.Title, &album.ArtistID, &album.ArtistName, &releaseDate, &album.CoverLink, &album.TrackCount, &album.CreatedAt, &album.UpdatedAt); err != nil {
+		return nil, err
+	}
+	album.ReleaseDate = releaseDate.String
+	return &album, nil
+}
+
+// CreateAlbum stores the album, creating its artist if needed, and sets its ID.
+func (r *AlbumRepository) CreateAlbum(ctx context.Context, album *models.Album) error {
+	defer metrics.ObserveQuery("CreateAlbum", time.Now())
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		slog.Error("Failed to begin transaction", "error", err)
+		return translateError(ctx, err, "failed to begin transaction")
+	}
+	defer tx.Rollback()
+
+	message := fmt.Sprintf("failed to create album %q", album.Title)
+
+	artistID, err := upsertArtist(ctx, tx, album.ArtistName)
+	if err != nil {
+		return translateError(ctx, err, message)
+	}
+
+	query := `INSERT INTO albums (title, artist_id, release_date, cover_link) VALUES ($1, $2, NULLIF($3, '')::date, $4) RETURNING id`
+	if err := tx.QueryRowContext(ctx, query, album.Title, artistID, album.ReleaseDate, album.CoverLink).Scan(&album.ID); err != nil {
+		slog.Error("Failed to create album", "title", album.Title, "error", err)
+		return translateError(ctx, err, message)
+	}
+
+	if err := tx.Commit(); err != nil {
+		slog.Error("Failed to commit album", "title", album.Title, "error", err)
+		return translateError(ctx, err, message)
+	}
+
+	slog.Info("Album created successfully", "id", album.ID, "title", album.Title)
+	return nil
+}
+
+func (r *AlbumRepository) GetAlbum(ctx context.Context, id int64) (*models.Album, error) {
+	defer metrics.ObserveQuery("GetAlbum", time.Now())
+
+	album, err := scanAlbum(r.db.QueryRowContext(ctx, albumSelect+` WHERE al.id = $1`, id))
+	if err == sql.ErrNoRows {
+		slog.Warn("No album found", "id", id)
+		return nil, apperrors.New(apperrors.ErrNotFound, "no album found with id %d", id)
+	} else if err != nil {
+		slog.Error("Failed to execute query", "id", id, "error", err)
+		return nil, translateError(ctx, err, "failed to execute query")
+	}
+
+	return album, nil
+}
+
+// ListAlbums returns a page of albums, newest first. A non-zero artistID limits the list to that artist.
+func (r *AlbumRepository) ListAlbums(ctx context.Context, artistID int64, page, pageSize int) ([]*models.Album, error) {
+	defer metrics.ObserveQuery("ListAlbums", time.Now())
+
+	query := albumSelect + ` WHERE $1 = 0 OR al.artist_id = $1
+	          ORDER BY al.release_date DESC NULLS LAST, al.id LIMIT $2 OFFSET $3`
+
+	rows, err := r.db.QueryContext(ctx, query, artistID, pageSize, (page-1)*pageSize)
+	if err != nil {
+		slog.Error("Failed to list albums", "error", err)
+		return nil, translateError(ctx, err, "failed to fetch albums")
+	}
+	defer rows.Close()
+
+	albums := []*models.Album{}
+	for rows.Next() {
+		album, err := scanAlbum(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan album row: %w", err)
+		}
+		albums = append(albums, album)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, translateError(ctx, err, "error iterating over rows")
+	}
+
+	return albums, nil
+}
+
+// UpdateAlbum replaces the album details. Tracks without a release date inherit the new album date.
+func (r *AlbumRepository) UpdateAlbum(ctx context.Context, id int64, album *models.Album) error {
+	defer metrics.ObserveQuery("UpdateAlbum", time.Now())
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		slog.Error("Failed to begin transaction", "error", err)
+		return translateError(ctx, err, "failed to begin transaction")
+	}
+	defer tx.Rollback()
+
+	message := fmt.Sprintf("failed to update album with id %d", id)
+
+	artistID, err := upsertArtist(ctx, tx, album.ArtistName)
+	if err != nil {
+		return translateError(ctx, err, message)
+	}
+
+	query := `UPDATE albums SET title = $1, artist_id = $2, release_date = NULLIF($3, '')::date, cover_link = $4, updated_at = now() WHERE id = $5`
+	result, err := tx.ExecContext(ctx, query, album.Title, artistID, album.ReleaseDate, album.CoverLink, id)
+	if err != nil {
+		slog.Error("Failed to update album", "id", id, "error", err)
+		return translateError(ctx, err, message)
+	}
+	if err := checkAlbumRowsAffected(result, id); err != nil {
+		return err
+	}
+
+	before, err := lockSongs(ctx, tx, `album_id = $1 AND release_date IS NULL`, id)
+	if err != nil {
+		return err
+	}
+
+	query = `UPDATE songs SET release_date = a.release_date, version = songs.version + 1 FROM albums a
+	         WHERE a.id = $1 AND songs.album_id = a.id AND songs.release_date IS NULL`
+	if _, err := tx.ExecContext(ctx, query, id); err != nil {
+		slog.Error("Failed to propagate album release date", "id", id, "error", err)
+		return translateError(ctx, err, message)
+	}
+	if err := recordSongUpdates(ctx, tx, before); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		slog.Error("Failed to commit album update", "id", id, "error", err)
+		return translateError(ctx, err, message)
+	}
+
+	slog.Info("Album updated successfully", "id", id)
+	return nil
+}
+
+// DeleteAlbum detaches the album's tracks and deletes it. The songs themselves are kept.
+func (r *AlbumRepository) DeleteAlbum(ctx context.Context, id int64) error {
+	defer metrics.ObserveQuery("DeleteAlbum", time.Now())
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		slog.Error("Failed to begin transaction", "error", err)
+		return translateError(ctx, err, "failed to begin transaction")
+	}
+	defer tx.Rollback()
+
+	message := fmt.Sprintf("failed to delete album with id %d", id)
+
+	before, err := lockSongs(ctx, tx, `album_id = $1`, id)
+	if err != nil {
+		return err
+	}
+
+	query := `UPDATE songs SET album_id = NULL, disc_number = NULL, track_number = NULL, version = version + 1 WHERE album_id = $1`
+	if _, err := tx.ExecContext(ctx, query, id); err != nil {
+		slog.Error("Failed to detach album tracks", "id", id, "error", err)
+		return translateError(ctx, err, message)
+	}
+	if err := recordSongUpdates(ctx, tx, before); err != nil {
+		return err
+	}
+
+	result, err := tx.ExecContext(ctx, `DELETE FROM albums WHERE id = $1`, id)
+	if err != nil {
+		slog.Error("Failed to delete album", "id", id, "error", err)
+		return translateError(ctx, err, message)
+	}
+	if err := checkAlbumRowsAffected(result, id); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		slog.Error("Failed to commit album deletion", "id", id, "error", err)
+		return translateError(ctx, err, message)
+	}
+
+	slog.Info("Album deleted successfully", "id", id)
+	return nil
+}
+
+// GetAlbumTracks returns the songs of the album ordered by disc and track number.
+func (r *AlbumRepository) GetAlbumTracks(ctx context.Context, id int64) ([]*models.Song, error) {
+	defer metrics.ObserveQuery("GetAlbumTracks", time.Now())
+
+	query := `SELECT ` + songColumns + ` FROM songs WHERE album_id = $1 AND deleted_at IS NULL ORDER BY disc_number, track_number`
+
+	rows, err := r.db.QueryContext(ctx, query, id)
+	if err != nil {
+		slog.Error("Failed to fetch album tracks", "id", id, "error", err)
+		return nil, translateError(ctx, err, "failed to fetch album tracks")
+	}
+	defer rows.Close()
+
+	songs := []*models.Song{}
+	for rows.Next() {
+		song, err := scanSong(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan song row: %w", err)
+		}
+		songs = append(songs, song)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, translateError(ctx, err, "error iterating over rows")
+	}
+
+	return songs, nil
+}
+
+// AttachSong places the song on the album at the given disc and track, moving it from any other album.
+// A song without a release date inherits the album's.
+func (r *AlbumRepository) AttachSong(ctx context.Context, albumID int64, songID string, disc, track int) error {
+	defer metrics.ObserveQuery("AttachSong", time.Now())
+
+	return r.reviseSong(ctx, songID, "failed to attach song", func(tx *sql.Tx) error {
+		query := `UPDATE songs SET album_id = a.id, disc_number = $2, track_number = $3,
+		                 release_date = COALESCE(songs.release_date, a.release_date), version = songs.version + 1
+		          FROM albums a WHERE a.id = $1 AND songs.id = $4`
+
+		result, err := tx.ExecContext(ctx, query, albumID, disc, track, songID)
+		if err != nil {
+			slog.Error("Failed to attach song", "album_id", albumID, "song_id", songID, "error", err)
+			return translateError(ctx, err, fmt.Sprintf("failed to put song on disc %d, track %d", disc, track))
+		}
+		if err := checkAlbumRowsAffected(result, albumID); err != nil {
+			return err
+		}
+
+		slog.Info("Song attached to album", "album_id", albumID, "song_id", songID, "disc", disc, "track", track)
+		return nil
+	})
+}
+
+// DetachSong removes the song from the album. The release date it inherited is kept.
+func (r *AlbumRepository) DetachSong(ctx context.Context, albumID int64, songID string) error {
+	defer metrics.ObserveQuery("DetachSong", time.Now())
+
+	return r.reviseSong(ctx, songID, "failed to detach song", func(tx *sql.Tx) error {
+		query := `UPDATE songs SET album_id = NULL, disc_number = NULL, track_number = NULL, version = version + 1 WHERE id = $1 AND album_id = $2`
+
+		result, err := tx.ExecContext(ctx, query, songID, albumID)
+		if err != nil {
+			slog.Error("Failed to detach song", "album_id", albumID, "song_id", songID, "error", err)
+			return translateError(ctx, err, "failed to detach song")
+		}
+
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to get rows affected: %w", err)
+		}
+		if rowsAffected == 0 {
+			return apperrors.New(apperrors.ErrNotFound, "song %s is not on album %d", songID, albumID)
+		}
+
+		slog.Info("Song detached from album", "album_id", albumID, "song_id", songID)
+		return nil
+	})
+}
+
+// reviseSong locks the song, applies update within the same transaction and records the change
+// as an update revision. Songs in the trash are not found.
+func (r *AlbumRepository) reviseSong(ctx context.Context, songID, message string, update func(tx *sql.Tx) error) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		slog.Error("Failed to begin transaction", "error", err)
+		return translateError(ctx, err, "failed to begin transaction")
+	}
+	defer tx.Rollback()
+
+	before, err := lockSong(ctx, tx, songID, 0)
+	if err != nil {
+		return err
+	}
+	if err := update(tx); err != nil {
+		return err
+	}
+	if err := recordSongUpdates(ctx, tx, []*models.Song{before}); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		slog.Error("Failed to commit song change", "song_id", songID, "error", err)
+		return translateError(ctx, err, message)
+	}
+	return nil
+}
+
+func checkAlbumRowsAffected(result sql.Result, id int64) error {
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		slog.Error("Failed to get rows affected", "id", id, "error", err)
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		slog.Warn("No rows affected", "id", id)
+		return apperrors.New(apperrors.ErrNotFound, "no album found with id %d", id)
+	}
+
+	return nil
+}
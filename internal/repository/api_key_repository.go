@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"music-library/internal/apperrors"
+	"music-library/internal/metrics"
+	"music-library/internal/models"
+	"time"
+)
+
+type APIKeyRepository struct {
+	db *sql.DB
+}
+
+func NewAPIKeyRepository(db *sql.DB) *APIKeyRepository {
+	return &APIKeyRepository{
+		db: db,
+	}
+}
+
+// CreateAPIKey stores a key under its hash and sets its ID and creation time.
+func (r *APIKeyRepository) CreateAPIKey(ctx context.Context, key *models.APIKey, hash string) error {
+	defer metrics.ObserveQuery("CreateAPIKey", time.Now())
+
+	query := `INSERT INTO api_keys (name, role, prefix, key_hash) VALUES ($1, $2, $3, $4) RETURNING id, created_at`
+
+	err := r.db.QueryRowContext(ctx, query, key.Name, key.Role, key.Prefix, hash).Scan(&key.ID, &key.CreatedAt)
+	if err != nil {
+		slog.Error("Failed to create API key", "name", key.Name, "error", err)
+		return translateError(ctx, err, "failed to create API key")
+	}
+
+	slog.Info("API key created", "id", key.ID, "name", key.Name, "role", key.Role)
+	return nil
+}
+
+// EnsureAPIKey stores the key unless a key with the same hash exists, revoked or not.
+func (r *APIKeyRepository) EnsureAPIKey(ctx context.Context, key *models.APIKey, hash string) error {
+	defer metrics.ObserveQuery("EnsureAPIKey", time.Now())
+
+	query := `INSERT INTO api_keys (name, role, prefix, key_hash) VALUES ($1, $2, $3, $4) ON CONFLICT (key_hash) DO NOTHING`
+
+	if _, err := r.db.ExecContext(ctx, query, key.Name, key.Role, key.Prefix, hash); err != nil {
+		slog.Error("Failed to ensure API key", "name", key.Name, "error", err)
+		return translateError(ctx, err, "failed to create API key")
+	}
+
+	return nil
+}
+
+// GetActiveAPIKey returns the unrevoked key with the given hash.
+func (r *APIKeyRepository) GetActiveAPIKey(ctx context.Context, hash string) (*models.APIKey, error) {
+	defer metrics.ObserveQuery("GetActiveAPIKey", time.Now())
+
+	query := `SELECT id, name, role, prefix, created_at, revoked_at FROM api_keys WHERE key_hash = $1 AND revoked_at IS NULL`
+
+	var key models.APIKey
+	err := r.db.QueryRowContext(ctx, query, hash).Scan(&key.ID, &key.Name, &key.Role, &key.Prefix, &key.CreatedAt, &key.RevokedAt)
+	if err == sql.ErrNoRows {
+		return nil, apperrors.New(apperrors.ErrNotFound, "no active API key matches")
+	} else if err != nil {
+		slog.Error("Failed to look up API key", "error", err)
+		return nil, translateError(ctx, err, "failed to look up API key")
+	}
+
+	return &key, nil
+}
+
+func (r *APIKeyRepository) ListAPIKeys(ctx context.Context) ([]*models.APIKey, error) {
+	defer metrics.ObserveQuery("ListAPIKeys", time.Now())
+
+	query := `SELECT id, name, role, prefix, created_at, revoked_at FROM api_keys ORDER BY id`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		slog.Error("Failed to list API keys", "error", err)
+		return nil, translateError(ctx, err, "failed to fetch API keys")
+	}
+	defer rows.Close()
+
+	keys := []*models.APIKey{}
+	for rows.Next() {
+		var key models.APIKey
+		if err := rows.Scan(&key.ID, &key.Name, &key.Role, &key.Prefix, &key.CreatedAt, &key.RevokedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan API key row: %w", err)
+		}
+		keys = append(keys, &key)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, translateError(ctx, err, "error iterating over rows")
+	}
+
+	return keys, nil
+}
+
+// RevokeAPIKey revokes an active key. Revoked keys are kept for auditing.
+func (r *APIKeyRepository) RevokeAPIKey(ctx context.Context, id int64) error {
+	defer metrics.ObserveQuery("RevokeAPIKey", time.Now())
+
+	query := `UPDATE api_keys SET revoked_at = now() WHERE id = $1 AND revoked_at IS NULL`
+
+	result, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		slog.Error("Failed to revoke API key", "id", id, "error", err)
+		return translateError(ctx, err, fmt.Sprintf("failed to revoke API key with id %d", id))
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return apperrors.New(apperrors.ErrNotFound, "no active API key found with id %d", id)
+	}
+
+	slog.Info("API key revoked", "id", id)
+	return nil
+}
@@ -0,0 +1,317 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"music-library/internal/apperrors"
+	"music-library/internal/metrics"
+	"music-library/internal/models"
+	"time"
+)
+
+type ArtistRepository struct {
+	db *sql.DB
+}
+
+func NewArtistRepository(db *sql.DB) *ArtistRepository {
+	return &ArtistRepository{
+		db: db,
+	}
+}
+
+// artistColumns selects an artist with the number of distinct songs it is credited on.
+const artistColumns = `a.id, a.name, a.created_at, a.updated_at,
+	(SELECT count(DISTINCT sa.song_id) FROM song_artists sa JOIN songs s ON s.id = sa.song_id WHERE sa.artist_id = a.id AND s.deleted_at IS NULL)`
+
+func scanArtist(row interface{ Scan(...interface{}) error }) (*models.Artist, error) {
+	var artist models.Artist
+	if err := row.Scan(&artist.ID, &artist.Name, &artist.CreatedAt, &artist.UpdatedAt, &artist.SongCount); err != nil {
+		return nil, err
+	}
+	return &artist, nil
+}
+
+func (r *ArtistRepository) CreateArtist(ctx context.Context, artist *models.Artist) error {
+	defer metrics.ObserveQuery("CreateArtist", time.Now())
+
+	query := `INSERT INTO artists (name, normalized_name) VALUES ($1, $2) RETURNING id, created_at, updated_at`
+
+	err := r.db.QueryRowContext(ctx, query, artist.Name, models.NormalizeArtistName(artist.Name)).Scan(&artist.ID, &artist.CreatedAt, &artist.UpdatedAt)
+	if err != nil {
+		slog.Error("Failed to create artist", "name", artist.Name, "error", err)
+		return translateError(ctx, err, fmt.Sprintf("failed to create artist %q", artist.Name))
+	}
+
+	slog.Info("Artist created successfully", "id", artist.ID, "name", artist.Name)
+	return nil
+}
+
+func (r *ArtistRepository) GetArtist(ctx context.Context, id int64) (*models.Artist, error) {
+	defer metrics.ObserveQuery("GetArtist", time.Now())
+
+	query := `SELECT ` + artistColumns + ` FROM artists a WHERE a.id = $1`
+
+	artist, err := scanArtist(r.db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		slog.Warn("No artist found", "id", id)
+		return nil, apperrors.New(apperrors.ErrNotFound, "no artist found with id %d", id)
+	} else if err != nil {
+		slog.Error("Failed to execute query", "id", id, "error", err)
+		return nil, translateError(ctx, err, "failed to execute query")
+	}
+
+	return artist, nil
+}
+
+// ListArtists returns a page of artists ordered by name. A non-empty name matches as a substring.
+func (r *ArtistRepository) ListArtists(ctx context.Context, name string, page, pageSize int) ([]*models.Artist, error) {
+	defer metrics.ObserveQuery("ListArtists", time.Now())
+
+	query := `SELECT ` + artistColumns + ` FROM artists a
+	          WHERE $1 = '' OR a.name ILIKE '%' || $1 || '%'
+	          ORDER BY a.normalized_name, a.id LIMIT $2 OFFSET $3`
+
+	rows, err := r.db.QueryContext(ctx, query, name, pageSize, (page-1)*pageSize)
+	if err != nil {
+		slog.Error("Failed to list artists", "error", err)
+		return nil, translateError(ctx, err, "failed to fetch artists")
+	}
+	defer rows.Close()
+
+	artists := []*models.Artist{}
+	for rows.Next() {
+		artist, err := scanArtist(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan artist row: %w", err)
+		}
+		artists = append(artists, artist)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, translateError(ctx, err, "error iterating over rows")
+	}
+
+	return artists, nil
+}
+
+// RenameArtist renames the artist and rewrites the group name of the songs it is the primary artist of,
+// so songs.group_name keeps matching the primary credit.
+func (r *ArtistRepository) RenameArtist(ctx context.Context, id int64, name string) error {
+	defer metrics.ObserveQuery("RenameArtist", time.Now())
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		slog.Error("Failed to begin transaction", "error", err)
+		return translateError(ctx, err, "failed to begin transaction")
+	}
+	defer tx.Rollback()
+
+	message := fmt.Sprintf("failed to rename artist with id %d", id)
+
+	query := `UPDATE artists SET name = $1, normalized_name = $2, updated_at = now() WHERE id = $3`
+	result, err := tx.ExecContext(ctx, query, name, models.NormalizeArtistName(name), id)
+	if err != nil {
+		slog.Error("Failed to rename artist", "id", id, "error", err)
+		return translateError(ctx, err, message)
+	}
+	if err := checkArtistRowsAffected(result, id); err != nil {
+		return err
+	}
+
+	before, err := lockSongs(ctx, tx, `id IN (SELECT song_id FROM song_artists WHERE artist_id = $1 AND role = 'primary')`, id)
+	if err != nil {
+		return err
+	}
+
+	query = `UPDATE songs SET group_name = $1, version = version + 1
+	         WHERE id IN (SELECT song_id FROM song_artists WHERE artist_id = $2 AND role = 'primary')`
+	if _, err := tx.ExecContext(ctx, query, name, id); err != nil {
+		slog.Error("Failed to update group name of songs", "artist_id", id, "error", err)
+		return translateError(ctx, err, message)
+	}
+	if err := recordSongUpdates(ctx, tx, before); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		slog.Error("Failed to commit artist rename", "id", id, "error", err)
+		return translateError(ctx, err, message)
+	}
+
+	slog.Info("Artist renamed successfully", "id", id, "name", name)
+	return nil
+}
+
+// DeleteArtist deletes an artist. Artists still credited on songs cannot be deleted.
+func (r *ArtistRepository) DeleteArtist(ctx context.Context, id int64) error {
+	defer metrics.ObserveQuery("DeleteArtist", time.Now())
+
+	query := `DELETE FROM artists WHERE id = $1`
+
+	result, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		slog.Error("Failed to delete artist", "id", id, "error", err)
+		return translateError(ctx, err, fmt.Sprintf("failed to delete artist with id %d", id))
+	}
+
+	if err := checkArtistRowsAffected(result, id); err != nil {
+		return err
+	}
+
+	slog.Info("Artist deleted successfully", "id", id)
+	return nil
+}
+
+// GetArtistSongs returns the songs the artist is credited on in any role.
+func (r *ArtistRepository) GetArtistSongs(ctx context.Context, id int64) ([]*models.Song, error) {
+	defer metrics.ObserveQuery("GetArtistSongs", time.Now())
+
+	query := `SELECT ` + songColumns + ` FROM songs
+	          WHERE id IN (SELECT song_id FROM song_artists WHERE artist_id = $1) AND deleted_at IS NULL
+	          ORDER BY release_date DESC NULLS LAST, id`
+
+	rows, err := r.db.QueryContext(ctx, query, id)
+	if err != nil {
+		slog.Error("Failed to fetch songs of artist", "id", id, "error", err)
+		return nil, translateError(ctx, err, "failed to fetch songs")
+	}
+	defer rows.Close()
+
+	songs := []*models.Song{}
+	for rows.Next() {
+		song, err := scanSong(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan song row: %w", err)
+		}
+		songs = append(songs, song)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, translateError(ctx, err, "error iterating over rows")
+	}
+
+	return songs, nil
+}
+
+// GetSongCredits returns the credits of a song, primary artist first.
+func (r *ArtistRepository) GetSongCredits(ctx context.Context, songID string) ([]models.SongCredit, error) {
+	defer metrics.ObserveQuery("GetSongCredits", time.Now())
+
+	query := `SELECT sa.song_id, sa.artist_id, a.name, sa.role, sa.position
+	          FROM song_artists sa JOIN artists a ON a.id = sa.artist_id
+	          WHERE sa.song_id = $1
+	          ORDER BY sa.role = 'primary' DESC, sa.role, sa.position, a.normalized_name`
+
+	rows, err := r.db.QueryContext(ctx, query, songID)
+	if err != nil {
+		slog.Error("Failed to fetch song credits", "song_id", songID, "error", err)
+		return nil, translateError(ctx, err, "failed to fetch song credits")
+	}
+	defer rows.Close()
+
+	credits := []models.SongCredit{}
+	for rows.Next() {
+		var credit models.SongCredit
+		if err := rows.Scan(&credit.SongID, &credit.ArtistID, &credit.ArtistName, &credit.Role, &credit.Position); err != nil {
+			return nil, fmt.Errorf("failed to scan credit row: %w", err)
+		}
+		credits = append(credits, credit)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, translateError(ctx, err, "error iterating over rows")
+	}
+
+	return credits, nil
+}
+
+// AddSongCredit credits an artist on a song. Crediting the same artist twice in a role updates its position.
+func (r *ArtistRepository) AddSongCredit(ctx context.Context, credit models.SongCredit) error {
+	defer metrics.ObserveQuery("AddSongCredit", time.Now())
+
+	query := `INSERT INTO song_artists (song_id, artist_id, role, position) VALUES ($1, $2, $3, $4)
+	          ON CONFLICT (song_id, artist_id, role) DO UPDATE SET position = EXCLUDED.position`
+
+	_, err := r.db.ExecContext(ctx, query, credit.SongID, credit.ArtistID, credit.Role, credit.Position)
+	if err != nil {
+		slog.Error("Failed to add song credit", "song_id", credit.SongID, "artist_id", credit.ArtistID, "role", credit.Role, "error", err)
+		return translateError(ctx, err, "failed to add song credit")
+	}
+
+	slog.Info("Song credit added", "song_id", credit.SongID, "artist_id", credit.ArtistID, "role", credit.Role)
+	return nil
+}
+
+// RemoveSongCredit removes the artist's credits on a song. An empty role removes every non-primary credit.
+func (r *ArtistRepository) RemoveSongCredit(ctx context.Context, songID string, artistID int64, role models.ArtistRole) error {
+	defer metrics.ObserveQuery("RemoveSongCredit", time.Now())
+
+	query := `DELETE FROM song_artists
+	          WHERE song_id = $1 AND artist_id = $2 AND role <> 'primary' AND ($3 = '' OR role = $3)`
+
+	result, err := r.db.ExecContext(ctx, query, songID, artistID, role)
+	if err != nil {
+		slog.Error("Failed to remove song credit", "song_id", songID, "artist_id", artistID, "error", err)
+		return translateError(ctx, err, "failed to remove song credit")
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return apperrors.New(apperrors.ErrNotFound, "artist %d is not credited on song %s", artistID, songID)
+	}
+
+	slog.Info("Song credit removed", "song_id", songID, "artist_id", artistID, "role", role)
+	return nil
+}
+
+// setPrimaryArtist makes the artist named name the primary artist of the song, creating the artist if needed.
+func setPrimaryArtist(ctx context.Context, tx *sql.Tx, songID, name string) error {
+	artistID, err := upsertArtist(ctx, tx, name)
+	if err != nil {
+		return translateError(ctx, err, "failed to save primary artist")
+	}
+
+	query := `DELETE FROM song_artists WHERE song_id = $1 AND role = 'primary' AND artist_id <> $2`
+	if _, err := tx.ExecContext(ctx, query, songID, artistID); err != nil {
+		slog.Error("Failed to remove previous primary artist", "song_id", songID, "error", err)
+		return translateError(ctx, err, "failed to save primary artist")
+	}
+
+	query = `INSERT INTO song_artists (song_id, artist_id, role) VALUES ($1, $2, 'primary') ON CONFLICT DO NOTHING`
+	if _, err := tx.ExecContext(ctx, query, songID, artistID); err != nil {
+		slog.Error("Failed to credit primary artist", "song_id", songID, "artist_id", artistID, "error", err)
+		return translateError(ctx, err, "failed to save primary artist")
+	}
+
+	return nil
+}
+
+// upsertArtist returns the ID of the artist named name, creating the artist if it does not exist.
+func upsertArtist(ctx context.Context, tx *sql.Tx, name string) (int64, error) {
+	var artistID int64
+	query := `INSERT INTO artists (name, normalized_name) VALUES ($1, $2)
+	          ON CONFLICT (normalized_name) DO UPDATE SET normalized_name = EXCLUDED.normalized_name
+	          RETURNING id`
+	if err := tx.QueryRowContext(ctx, query, models.CleanArtistName(name), models.NormalizeArtistName(name)).Scan(&artistID); err != nil {
+		slog.Error("Failed to upsert artist", "name", name, "error", err)
+		return 0, err
+	}
+	return artistID, nil
+}
+
+func checkArtistRowsAffected(result sql.Result, id int64) error {
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		slog.Error("Failed to get rows affected", "id", id, "error", err)
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		slog.Warn("No rows affected", "id", id)
+		return apperrors.New(apperrors.ErrNotFound, "no artist found with id %d", id)
+	}
+
+	return nil
+}
@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"music-library/internal/apperrors"
+
+	"github.com/lib/pq"
+)
+
+// PostgreSQL error codes mapped to domain errors.
+const (
+	pqUniqueViolation       = "23505"
+	pqForeignKeyViolation   = "23503"
+	pqInvalidTextFormat     = "22P02"
+	pqInvalidDatetimeFormat = "22007"
+	pqDatetimeOverflow      = "22008"
+	pqStringTooLong         = "22001"
+)
+
+// translateError maps driver errors to domain errors, keeping the driver error as the cause.
+// message describes the failed operation and is shown to clients. When ctx is done the
+// context error is returned instead, since the driver only reports a cancelled statement.
+func translateError(ctx context.Context, err error, message string) error {
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return fmt.Errorf("%s: %w", message, ctxErr)
+	}
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch pqErr.Code {
+		case pqUniqueViolation:
+			return apperrors.Wrap(apperrors.ErrDuplicate, err, "%s: already exists", message)
+		case pqForeignKeyViolation:
+			return apperrors.Wrap(apperrors.ErrConflict, err, "%s: still referenced", message)
+		case pqInvalidTextFormat, pqInvalidDatetimeFormat, pqDatetimeOverflow, pqStringTooLong:
+			return apperrors.Wrap(apperrors.ErrValidation, err, "%s: invalid value", message)
+		}
+	}
+
+	return fmt.Errorf("%s: %w", message, err)
+}
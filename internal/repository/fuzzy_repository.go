@@ -0,0 +1,158 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"music-library/internal/metrics"
+	"music-library/internal/models"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// songTitle is the group and song name of a song, matching the expression of idx_songs_title_trgm.
+const songTitle = `(group_name || ' ' || song_name)`
+
+// maxSuggestionDistance is the largest trigram word distance of a "did you mean" suggestion.
+const maxSuggestionDistance = 0.8
+
+// FuzzySearchSongs returns a page of the songs whose names contain words similar to the search terms,
+// best match first, with their mean word similarity as rank.
+func (r *SongRepository) FuzzySearchSongs(ctx context.Context, q *models.FuzzyQuery, page, pageSize int) ([]*models.Song, error) {
+	defer metrics.ObserveQuery("FuzzySearchSongs", time.Now())
+
+	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		slog.Error("Failed to begin transaction", "error", err)
+		return nil, translateError(ctx, err, "failed to begin transaction")
+	}
+	defer tx.Rollback()
+
+	// The <% operator compares against this setting, which keeps the condition indexable.
+	threshold := strconv.FormatFloat(q.Threshold, 'f', -1, 64)
+	if _, err := tx.ExecContext(ctx, `SELECT set_config('pg_trgm.word_similarity_threshold', $1, true)`, threshold); err != nil {
+		slog.Error("Failed to set similarity threshold", "error", err)
+		return nil, translateError(ctx, err, "failed to search songs")
+	}
+
+	var (
+		args       []interface{}
+		conditions []string
+		scores     []string
+	)
+	for _, term := range []struct{ value, expr string }{
+		{q.Q, songTitle},
+		{q.Group, "group_name"},
+		{q.Song, "song_name"},
+	} {
+		if term.value == "" {
+			continue
+		}
+		args = append(args, term.value)
+		conditions = append(conditions, fmt.Sprintf("$%d <%% %s", len(args), term.expr))
+		scores = append(scores, fmt.Sprintf("word_similarity($%d, %s)", len(args), term.expr))
+	}
+
+	args = append(args, pageSize, (page-1)*pageSize)
+	query := `SELECT ` + songColumns + `, (` + strings.Join(scores, " + ") + fmt.Sprintf(`) / %d AS score
+	          FROM songs WHERE deleted_at IS NULL AND `, len(scores)) + strings.Join(conditions, " AND ") + `
+	          ORDER BY score DESC, ` + songSortKey + fmt.Sprintf(` DESC, id DESC LIMIT $%d OFFSET $%d`, len(args)-1, len(args))
+
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		slog.Error("Failed to execute fuzzy search", "error", err)
+		return nil, translateError(ctx, err, "failed to search songs")
+	}
+	defer rows.Close()
+
+	songs := []*models.Song{}
+	for rows.Next() {
+		var score float64
+		song, err := scanSong(rows, &score)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan song row: %w", err)
+		}
+		song.Rank = score
+		songs = append(songs, song)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, translateError(ctx, err, "error iterating over rows")
+	}
+
+	return songs, nil
+}
+
+// SuggestNames returns up to limit distinct names closest to term that differ from it, closest first.
+// field is "q" for titles, "group" or "song".
+func (r *SongRepository) SuggestNames(ctx context.Context, field, term string, limit int) ([]string, error) {
+	defer metrics.ObserveQuery("SuggestNames", time.Now())
+
+	expr, ok := map[string]string{"q": songTitle, "group": "group_name", "song": "song_name"}[field]
+	if !ok {
+		return nil, fmt.Errorf("unknown suggestion field %q", field)
+	}
+
+	// The nearest rows come from the trigram index; names repeat across songs, so more are read than returned.
+	query := `SELECT name FROM (
+	              SELECT ` + expr + ` AS name, $1 <<-> ` + expr + ` AS distance
+	              FROM songs WHERE deleted_at IS NULL
+	              ORDER BY $1 <<-> ` + expr + ` LIMIT $2
+	          ) nearest
+	          WHERE distance < $3 AND lower(name) <> lower($1)
+	          GROUP BY name ORDER BY min(distance), name LIMIT $4`
+
+	rows, err := r.db.QueryContext(ctx, query, term, limit*10, maxSuggestionDistance, limit)
+	if err != nil {
+		slog.Error("Failed to fetch name suggestions", "field", field, "error", err)
+		return nil, translateError(ctx, err, "failed to fetch suggestions")
+	}
+	defer rows.Close()
+
+	names := []string{}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("failed to scan suggestion row: %w", err)
+		}
+		names = append(names, name)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, translateError(ctx, err, "error iterating over rows")
+	}
+
+	return names, nil
+}
+
+// Autocomplete returns up to limit songs whose group and song name best match the typed text.
+// The rows are read in distance order straight from idx_songs_title_trgm, so the cost does not
+// grow with the size of the library.
+func (r *SongRepository) Autocomplete(ctx context.Context, term string, limit int) ([]models.SongSuggestion, error) {
+	defer metrics.ObserveQuery("Autocomplete", time.Now())
+
+	query := `SELECT id, group_name, song_name, 1 - ($1 <<-> ` + songTitle + `)
+	          FROM songs WHERE deleted_at IS NULL AND ($1 <<-> ` + songTitle + `) < 1
+	          ORDER BY $1 <<-> ` + songTitle + ` LIMIT $2`
+
+	rows, err := r.db.QueryContext(ctx, query, term, limit)
+	if err != nil {
+		slog.Error("Failed to autocomplete", "error", err)
+		return nil, translateError(ctx, err, "failed to autocomplete")
+	}
+	defer rows.Close()
+
+	suggestions := []models.SongSuggestion{}
+	for rows.Next() {
+		var s models.SongSuggestion
+		if err := rows.Scan(&s.ID, &s.GroupName, &s.SongName, &s.Score); err != nil {
+			return nil, fmt.Errorf("failed to scan suggestion row: %w", err)
+		}
+		suggestions = append(suggestions, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, translateError(ctx, err, "error iterating over rows")
+	}
+
+	return suggestions, nil
+}
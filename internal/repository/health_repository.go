@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"music-library/internal/metrics"
+	"time"
+)
+
+type HealthRepository struct {
+	db *sql.DB
+}
+
+func NewHealthRepository(db *sql.DB) *HealthRepository {
+	return &HealthRepository{
+		db: db,
+	}
+}
+
+func (r *HealthRepository) Ping(ctx context.Context) error {
+	defer metrics.ObserveQuery("Ping", time.Now())
+
+	if err := r.db.PingContext(ctx); err != nil {
+		return fmt.Errorf("failed to ping database: %w", err)
+	}
+	return nil
+}
+
+// MigrationVersion returns the schema version recorded by golang-migrate and whether it is dirty.
+func (r *HealthRepository) MigrationVersion(ctx context.Context) (uint, bool, error) {
+	defer metrics.ObserveQuery("MigrationVersion", time.Now())
+
+	query := `SELECT version, dirty FROM schema_migrations LIMIT 1`
+
+	var version int64
+	var dirty bool
+	err := r.db.QueryRowContext(ctx, query).Scan(&version, &dirty)
+	if err == sql.ErrNoRows {
+		return 0, false, fmt.Errorf("no schema version recorded")
+	} else if err != nil {
+		return 0, false, fmt.Errorf("failed to read schema version: %w", err)
+	}
+
+	return uint(version), dirty, nil
+}
@@ -0,0 +1,153 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"music-library/internal/apperrors"
+	"music-library/internal/metrics"
+	"music-library/internal/models"
+	"time"
+)
+
+type JobRepository struct {
+	db *sql.DB
+}
+
+func NewJobRepository(db *sql.DB) *JobRepository {
+	return &JobRepository{
+		db: db,
+	}
+}
+
+func (r *JobRepository) CreateJob(ctx context.Context, job models.Job) error {
+	defer metrics.ObserveQuery("CreateJob", time.Now())
+
+	query := `INSERT INTO song_jobs (id, group_name, song_name, status, actor, actor_role, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
+
+	_, err := r.db.ExecContext(ctx, query, job.ID, job.GroupName, job.SongName, job.Status, job.Actor, job.ActorRole, job.CreatedAt, job.UpdatedAt)
+	if err != nil {
+		slog.Error("Failed to create job", "group_name", job.GroupName, "song_name", job.SongName, "error", err)
+		return translateError(ctx, err, "failed to create job")
+	}
+
+	slog.Info("Job created successfully", "id", job.ID)
+	return nil
+}
+
+func (r *JobRepository) GetJob(ctx context.Context, id string) (*models.Job, error) {
+	defer metrics.ObserveQuery("GetJob", time.Now())
+
+	query := `SELECT id, group_name, song_name, status, attempts, last_error, song_id, created_at, updated_at FROM song_jobs WHERE id = $1`
+
+	var job models.Job
+	err := r.db.QueryRowContext(ctx, query, id).Scan(&job.ID, &job.GroupName, &job.SongName, &job.Status, &job.Attempts, &job.LastError, &job.SongID, &job.CreatedAt, &job.UpdatedAt)
+	if err == sql.ErrNoRows {
+		slog.Warn("No job found", "id", id)
+		return nil, apperrors.New(apperrors.ErrNotFound, "no job found with id %s", id)
+	} else if err != nil {
+		slog.Error("Failed to execute query", "id", id, "error", err)
+		return nil, translateError(ctx, err, "failed to execute query")
+	}
+
+	return &job, nil
+}
+
+// ClaimJob marks the oldest runnable job as running for the lease duration and returns it.
+// Jobs left running by a crashed worker become claimable again once their lease expires.
+// It returns nil when there is nothing to do.
+func (r *JobRepository) ClaimJob(ctx context.Context, lease time.Duration) (*models.Job, error) {
+	defer metrics.ObserveQuery("ClaimJob", time.Now())
+
+	query := `UPDATE song_jobs
+	          SET status = 'running', attempts = attempts + 1, locked_until = now() + $1 * interval '1 millisecond', updated_at = now()
+	          WHERE id = (
+	              SELECT id FROM song_jobs
+	              WHERE (status = 'pending' AND run_after <= now())
+	                 OR (status = 'running' AND locked_until < now())
+	              ORDER BY run_after
+	              LIMIT 1
+	              FOR UPDATE SKIP LOCKED
+	          )
+	          RETURNING id, group_name, song_name, status, attempts, last_error, song_id, created_at, updated_at, actor, actor_role`
+
+	var job models.Job
+	err := r.db.QueryRowContext(ctx, query, lease.Milliseconds()).Scan(&job.ID, &job.GroupName, &job.SongName, &job.Status, &job.Attempts, &job.LastError, &job.SongID, &job.CreatedAt, &job.UpdatedAt, &job.Actor, &job.ActorRole)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		slog.Error("Failed to claim job", "error", err)
+		return nil, translateError(ctx, err, "failed to claim job")
+	}
+
+	slog.Info("Job claimed", "id", job.ID, "attempts", job.Attempts)
+	return &job, nil
+}
+
+// CompleteJob records the song added by the job claimed at attempt.
+func (r *JobRepository) CompleteJob(ctx context.Context, id string, attempt int, songID string) error {
+	defer metrics.ObserveQuery("CompleteJob", time.Now())
+
+	query := `UPDATE song_jobs SET status = 'succeeded', song_id = $1, last_error = '', locked_until = NULL, updated_at = now()
+	          WHERE id = $2 AND status = 'running' AND attempts = $3`
+
+	result, err := r.db.ExecContext(ctx, query, songID, id, attempt)
+	if err != nil {
+		slog.Error("Failed to complete job", "id", id, "error", err)
+		return translateError(ctx, err, fmt.Sprintf("failed to complete job with id %s", id))
+	}
+
+	return checkJobLease(result, id, attempt)
+}
+
+// RetryJob puts the job claimed at attempt back in the queue to be picked up again after delay.
+func (r *JobRepository) RetryJob(ctx context.Context, id string, attempt int, delay time.Duration, lastError string) error {
+	defer metrics.ObserveQuery("RetryJob", time.Now())
+
+	query := `UPDATE song_jobs
+	          SET status = 'pending', last_error = $1, run_after = now() + $2 * interval '1 millisecond', locked_until = NULL, updated_at = now()
+	          WHERE id = $3 AND status = 'running' AND attempts = $4`
+
+	result, err := r.db.ExecContext(ctx, query, lastError, delay.Milliseconds(), id, attempt)
+	if err != nil {
+		slog.Error("Failed to reschedule job", "id", id, "error", err)
+		return translateError(ctx, err, fmt.Sprintf("failed to reschedule job with id %s", id))
+	}
+
+	return checkJobLease(result, id, attempt)
+}
+
+// FailJob marks the job claimed at attempt as failed for good.
+func (r *JobRepository) FailJob(ctx context.Context, id string, attempt int, lastError string) error {
+	defer metrics.ObserveQuery("FailJob", time.Now())
+
+	query := `UPDATE song_jobs SET status = 'failed', last_error = $1, locked_until = NULL, updated_at = now()
+	          WHERE id = $2 AND status = 'running' AND attempts = $3`
+
+	result, err := r.db.ExecContext(ctx, query, lastError, id, attempt)
+	if err != nil {
+		slog.Error("Failed to mark job as failed", "id", id, "error", err)
+		return translateError(ctx, err, fmt.Sprintf("failed to mark job with id %s as failed", id))
+	}
+
+	return checkJobLease(result, id, attempt)
+}
+
+// checkJobLease returns a conflict when an update of a claimed job matched no row. The updates
+// only apply while the job is running under the same claim; a job claimed again after its lease
+// expired has more attempts, and the new claim owns its outcome.
+func checkJobLease(result sql.Result, id string, attempt int) error {
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		slog.Error("Failed to get rows affected", "id", id, "error", err)
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		slog.Warn("Job lease lost", "id", id, "attempt", attempt)
+		return apperrors.New(apperrors.ErrConflict, "lease of job %s at attempt %d was lost", id, attempt)
+	}
+
+	return nil
+}
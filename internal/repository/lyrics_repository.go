@@ -0,0 +1,169 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"music-library/internal/apperrors"
+	"music-library/internal/metrics"
+	"music-library/internal/models"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// replaceSongSections stores the sections parsed from text as the song's lyrics sections.
+func replaceSongSections(ctx context.Context, tx *sql.Tx, id, text string) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM song_sections WHERE song_id = $1`, id); err != nil {
+		return translateError(ctx, err, "failed to replace lyrics sections")
+	}
+
+	for _, section := range models.ParseLyrics(text) {
+		query := `INSERT INTO song_sections (song_id, position, kind, lines) VALUES ($1, $2, $3, $4)`
+
+		if _, err := tx.ExecContext(ctx, query, id, section.Number, section.Kind, pq.Array(section.Lines)); err != nil {
+			return translateError(ctx, err, "failed to store lyrics section")
+		}
+	}
+	return nil
+}
+
+// GetSongSections returns the song's lyrics sections numbered from through to, inclusively,
+// and the number of sections the song has.
+func (r *SongRepository) GetSongSections(ctx context.Context, id string, from, to int) ([]models.LyricsSection, int, error) {
+	defer metrics.ObserveQuery("GetSongSections", time.Now())
+
+	return r.songSections(ctx, id, `AND position BETWEEN $2 AND $3 ORDER BY position`, func(all []models.LyricsSection) []models.LyricsSection {
+		picked := []models.LyricsSection{}
+		for _, section := range all {
+			if section.Number >= from && section.Number <= to {
+				picked = append(picked, section)
+			}
+		}
+		return picked
+	}, from, to)
+}
+
+// GetSongTextPaginated returns a page of the song's lyrics sections and the number of sections the song has.
+func (r *SongRepository) GetSongTextPaginated(ctx context.Context, id string, page, pageSize int) ([]models.LyricsSection, int, error) {
+	defer metrics.ObserveQuery("GetSongTextPaginated", time.Now())
+
+	offset := (page - 1) * pageSize
+	return r.songSections(ctx, id, `ORDER BY position LIMIT $2 OFFSET $3`, func(all []models.LyricsSection) []models.LyricsSection {
+		start := min(offset, len(all))
+		return all[start:min(start+pageSize, len(all))]
+	}, pageSize, offset)
+}
+
+// songSections reads the sections of a song selected by window, which continues the WHERE clause
+// of song_sections and may use $2 and beyond. The lyrics of a song the backfill has not reached yet
+// are parsed here instead, and pick selects from them what window would have. A song that does not
+// exist or is in the trash is not found.
+func (r *SongRepository) songSections(ctx context.Context, id, window string, pick func([]models.LyricsSection) []models.LyricsSection, args ...interface{}) ([]models.LyricsSection, int, error) {
+	// The song row comes back even when no section is selected, carrying the total, and the
+	// lyrics when they have not been parsed into sections.
+	query := `SELECT sec.position, sec.kind, sec.lines,
+	                 (SELECT COUNT(*) FROM song_sections WHERE song_id = songs.id),
+	                 CASE WHEN songs.lyrics_parsed THEN NULL ELSE songs.text END
+	          FROM songs
+	          LEFT JOIN LATERAL (SELECT * FROM song_sections WHERE song_id = songs.id ` + window + `) sec ON true
+	          WHERE songs.id = $1 AND songs.deleted_at IS NULL
+	          ORDER BY sec.position`
+
+	rows, err := r.db.QueryContext(ctx, query, append([]interface{}{id}, args...)...)
+	if err != nil {
+		slog.Error("Failed to execute lyrics query", "id", id, "error", err)
+		return nil, 0, translateError(ctx, err, "failed to fetch song lyrics")
+	}
+	defer rows.Close()
+
+	var (
+		sections = []models.LyricsSection{}
+		total    int
+		found    bool
+		unparsed sql.NullString
+	)
+	for rows.Next() {
+		var (
+			position sql.NullInt32
+			kind     sql.NullString
+			lines    []string
+		)
+		if err := rows.Scan(&position, &kind, pq.Array(&lines), &total, &unparsed); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan lyrics section row: %w", err)
+		}
+		found = true
+		if !position.Valid {
+			continue
+		}
+		if lines == nil {
+			lines = []string{}
+		}
+		sections = append(sections, models.LyricsSection{Number: int(position.Int32), Kind: models.SectionKind(kind.String), Lines: lines})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, translateError(ctx, err, "error iterating over rows")
+	}
+
+	if !found {
+		slog.Warn("No song found", "id", id)
+		return nil, 0, apperrors.New(apperrors.ErrNotFound, "no song found with id %s", id)
+	}
+	if unparsed.Valid {
+		all := models.ParseLyrics(unparsed.String)
+		return pick(all), len(all), nil
+	}
+	return sections, total, nil
+}
+
+// BackfillSongSections parses the lyrics of up to limit songs flagged as not parsed yet, the songs
+// added before sections were stored, and returns how many songs were parsed.
+func (r *SongRepository) BackfillSongSections(ctx context.Context, limit int) (int, error) {
+	defer metrics.ObserveQuery("BackfillSongSections", time.Now())
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		slog.Error("Failed to begin transaction", "error", err)
+		return 0, translateError(ctx, err, "failed to begin transaction")
+	}
+	defer tx.Rollback()
+
+	query := `SELECT id, text FROM songs WHERE NOT lyrics_parsed ORDER BY id LIMIT $1 FOR UPDATE SKIP LOCKED`
+
+	rows, err := tx.QueryContext(ctx, query, limit)
+	if err != nil {
+		slog.Error("Failed to select songs without sections", "error", err)
+		return 0, translateError(ctx, err, "failed to select songs without sections")
+	}
+	lyrics := map[string]string{}
+	for rows.Next() {
+		var id, text string
+		if err := rows.Scan(&id, &text); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan song row: %w", err)
+		}
+		lyrics[id] = text
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, translateError(ctx, err, "error iterating over rows")
+	}
+
+	for id, text := range lyrics {
+		if err := replaceSongSections(ctx, tx, id, text); err != nil {
+			slog.Error("Failed to backfill lyrics sections", "id", id, "error", err)
+			return 0, err
+		}
+		if _, err := tx.ExecContext(ctx, `UPDATE songs SET lyrics_parsed = true WHERE id = $1`, id); err != nil {
+			slog.Error("Failed to mark lyrics as parsed", "id", id, "error", err)
+			return 0, translateError(ctx, err, "failed to backfill lyrics sections")
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		slog.Error("Failed to commit lyrics sections", "error", err)
+		return 0, translateError(ctx, err, "failed to backfill lyrics sections")
+	}
+	return len(lyrics), nil
+}
@@ -0,0 +1,273 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"music-library/internal/apperrors"
+	"music-library/internal/metrics"
+	"music-library/internal/models"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+type PlaylistRepository struct {
+	db *sql.DB
+}
+
+func NewPlaylistRepository(db *sql.DB) *PlaylistRepository {
+	return &PlaylistRepository{
+		db: db,
+	}
+}
+
+// playlistSelect selects playlists with their number of entries, in the order read by scanPlaylist.
+const playlistSelect = `SELECT p.id, p.name, p.version,
+	(SELECT count(*) FROM playlist_entries e JOIN songs s ON s.id = e.song_id WHERE e.playlist_id = p.id AND s.deleted_at IS NULL), p.created_at, p.updated_at
+	FROM playlists p`
+
+func scanPlaylist(row interface{ Scan(...interface{}) error }) (*models.Playlist, error) {
+	var playlist models.Playlist
+	if err := row.Scan(&playlist.ID, &playlist.Name, &playlist.Version, &playlist.SongCount, &playlist.CreatedAt, &playlist.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return &playlist, nil
+}
+
+func (r *PlaylistRepository) CreatePlaylist(ctx context.Context, playlist *models.Playlist) error {
+	defer metrics.ObserveQuery("CreatePlaylist", time.Now())
+
+	query := `INSERT INTO playlists (name) VALUES ($1) RETURNING id, version, created_at, updated_at`
+
+	err := r.db.QueryRowContext(ctx, query, playlist.Name).Scan(&playlist.ID, &playlist.Version, &playlist.CreatedAt, &playlist.UpdatedAt)
+	if err != nil {
+		slog.Error("Failed to create playlist", "name", playlist.Name, "error", err)
+		return translateError(ctx, err, "failed to create playlist")
+	}
+
+	slog.Info("Playlist created successfully", "id", playlist.ID)
+	return nil
+}
+
+func (r *PlaylistRepository) GetPlaylist(ctx context.Context, id int64) (*models.Playlist, error) {
+	defer metrics.ObserveQuery("GetPlaylist", time.Now())
+
+	playlist, err := scanPlaylist(r.db.QueryRowContext(ctx, playlistSelect+` WHERE p.id = $1`, id))
+	if err == sql.ErrNoRows {
+		slog.Warn("No playlist found", "id", id)
+		return nil, apperrors.New(apperrors.ErrNotFound, "no playlist found with id %d", id)
+	} else if err != nil {
+		slog.Error("Failed to execute query", "id", id, "error", err)
+		return nil, translateError(ctx, err, "failed to execute query")
+	}
+
+	return playlist, nil
+}
+
+// ListPlaylists returns a page of playlists, most recently changed first, without their songs.
+func (r *PlaylistRepository) ListPlaylists(ctx context.Context, page, pageSize int) ([]*models.Playlist, error) {
+	defer metrics.ObserveQuery("ListPlaylists", time.Now())
+
+	query := playlistSelect + ` ORDER BY p.updated_at DESC, p.id LIMIT $1 OFFSET $2`
+
+	rows, err := r.db.QueryContext(ctx, query, pageSize, (page-1)*pageSize)
+	if err != nil {
+		slog.Error("Failed to list playlists", "error", err)
+		return nil, translateError(ctx, err, "failed to fetch playlists")
+	}
+	defer rows.Close()
+
+	playlists := []*models.Playlist{}
+	for rows.Next() {
+		playlist, err := scanPlaylist(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan playlist row: %w", err)
+		}
+		playlists = append(playlists, playlist)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, translateError(ctx, err, "error iterating over rows")
+	}
+
+	return playlists, nil
+}
+
+// GetPlaylistSongs returns the songs of the playlist in playlist order.
+func (r *PlaylistRepository) GetPlaylistSongs(ctx context.Context, id int64) ([]*models.Song, error) {
+	defer metrics.ObserveQuery("GetPlaylistSongs", time.Now())
+
+	query := `SELECT ` + songColumns + ` FROM songs
+	          JOIN playlist_entries e ON e.song_id = songs.id
+	          WHERE e.playlist_id = $1 AND songs.deleted_at IS NULL ORDER BY e.position`
+
+	rows, err := r.db.QueryContext(ctx, query, id)
+	if err != nil {
+		slog.Error("Failed to fetch playlist songs", "id", id, "error", err)
+		return nil, translateError(ctx, err, "failed to fetch playlist songs")
+	}
+	defer rows.Close()
+
+	songs := []*models.Song{}
+	for rows.Next() {
+		song, err := scanSong(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan song row: %w", err)
+		}
+		songs = append(songs, song)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, translateError(ctx, err, "error iterating over rows")
+	}
+
+	return songs, nil
+}
+
+// RenamePlaylist renames the playlist and bumps its version.
+func (r *PlaylistRepository) RenamePlaylist(ctx context.Context, id int64, name string) error {
+	defer metrics.ObserveQuery("RenamePlaylist", time.Now())
+
+	query := `UPDATE playlists SET name = $1, version = version + 1, updated_at = now() WHERE id = $2`
+
+	result, err := r.db.ExecContext(ctx, query, name, id)
+	if err != nil {
+		slog.Error("Failed to rename playlist", "id", id, "error", err)
+		return translateError(ctx, err, fmt.Sprintf("failed to rename playlist with id %d", id))
+	}
+
+	if err := checkPlaylistRowsAffected(result, id); err != nil {
+		return err
+	}
+
+	slog.Info("Playlist renamed successfully", "id", id)
+	return nil
+}
+
+func (r *PlaylistRepository) DeletePlaylist(ctx context.Context, id int64) error {
+	defer metrics.ObserveQuery("DeletePlaylist", time.Now())
+
+	result, err := r.db.ExecContext(ctx, `DELETE FROM playlists WHERE id = $1`, id)
+	if err != nil {
+		slog.Error("Failed to delete playlist", "id", id, "error", err)
+		return translateError(ctx, err, fmt.Sprintf("failed to delete playlist with id %d", id))
+	}
+
+	if err := checkPlaylistRowsAffected(result, id); err != nil {
+		return err
+	}
+
+	slog.Info("Playlist deleted successfully", "id", id)
+	return nil
+}
+
+// UpdatePlaylistSongs replaces the song order of a playlist in one transaction. fn receives the
+// current song IDs in order and returns the new ones; songs it leaves out are removed and songs
+// it introduces are added. The playlist row stays locked until commit, so concurrent updates
+// are applied one after another. Songs in the trash are hidden from fn and kept at the end of
+// the playlist, so they are back in it when restored. A positive expectedVersion must match the
+// current version, otherwise ErrConflict is returned. The new version is returned.
+func (r *PlaylistRepository) UpdatePlaylistSongs(ctx context.Context, id int64, expectedVersion int, fn func([]string) ([]string, error)) (int, error) {
+	defer metrics.ObserveQuery("UpdatePlaylistSongs", time.Now())
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		slog.Error("Failed to begin transaction", "error", err)
+		return 0, translateError(ctx, err, "failed to begin transaction")
+	}
+	defer tx.Rollback()
+
+	message := fmt.Sprintf("failed to update playlist with id %d", id)
+
+	var version int
+	err = tx.QueryRowContext(ctx, `SELECT version FROM playlists WHERE id = $1 FOR UPDATE`, id).Scan(&version)
+	if err == sql.ErrNoRows {
+		return 0, apperrors.New(apperrors.ErrNotFound, "no playlist found with id %d", id)
+	} else if err != nil {
+		slog.Error("Failed to lock playlist", "id", id, "error", err)
+		return 0, translateError(ctx, err, message)
+	}
+	if expectedVersion > 0 && expectedVersion != version {
+		return 0, apperrors.New(apperrors.ErrConflict, "playlist %d was modified concurrently: version is %d, not %d", id, version, expectedVersion)
+	}
+
+	current, err := playlistSongIDs(ctx, tx, id, false)
+	if err != nil {
+		return 0, translateError(ctx, err, message)
+	}
+	trashed, err := playlistSongIDs(ctx, tx, id, true)
+	if err != nil {
+		return 0, translateError(ctx, err, message)
+	}
+
+	next, err := fn(current)
+	if err != nil {
+		return 0, err
+	}
+	next = append(next, trashed...)
+
+	query := `DELETE FROM playlist_entries WHERE playlist_id = $1 AND NOT (song_id = ANY($2))`
+	if _, err := tx.ExecContext(ctx, query, id, pq.Array(next)); err != nil {
+		slog.Error("Failed to remove playlist entries", "id", id, "error", err)
+		return 0, translateError(ctx, err, message)
+	}
+
+	query = `INSERT INTO playlist_entries (playlist_id, song_id, position)
+	         SELECT $1, song_id, position FROM unnest($2::text[]) WITH ORDINALITY AS t(song_id, position)
+	         ON CONFLICT (playlist_id, song_id) DO UPDATE SET position = EXCLUDED.position`
+	if _, err := tx.ExecContext(ctx, query, id, pq.Array(next)); err != nil {
+		slog.Error("Failed to write playlist entries", "id", id, "error", err)
+		return 0, translateError(ctx, err, message)
+	}
+
+	query = `UPDATE playlists SET version = version + 1, updated_at = now() WHERE id = $1 RETURNING version`
+	if err := tx.QueryRowContext(ctx, query, id).Scan(&version); err != nil {
+		slog.Error("Failed to bump playlist version", "id", id, "error", err)
+		return 0, translateError(ctx, err, message)
+	}
+
+	if err := tx.Commit(); err != nil {
+		slog.Error("Failed to commit playlist update", "id", id, "error", err)
+		return 0, translateError(ctx, err, message)
+	}
+
+	slog.Info("Playlist songs updated", "id", id, "songs", len(next), "version", version)
+	return version, nil
+}
+
+// playlistSongIDs returns the playlist's song IDs in order: those of songs in the trash if trashed is set, the others otherwise.
+func playlistSongIDs(ctx context.Context, tx *sql.Tx, id int64, trashed bool) ([]string, error) {
+	query := `SELECT e.song_id FROM playlist_entries e JOIN songs s ON s.id = e.song_id
+	          WHERE e.playlist_id = $1 AND (s.deleted_at IS NOT NULL) = $2 ORDER BY e.position`
+	rows, err := tx.QueryContext(ctx, query, id, trashed)
+	if err != nil {
+		slog.Error("Failed to read playlist entries", "id", id, "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := []string{}
+	for rows.Next() {
+		var songID string
+		if err := rows.Scan(&songID); err != nil {
+			return nil, err
+		}
+		ids = append(ids, songID)
+	}
+	return ids, rows.Err()
+}
+
+func checkPlaylistRowsAffected(result sql.Result, id int64) error {
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		slog.Error("Failed to get rows affected", "id", id, "error", err)
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		slog.Warn("No rows affected", "id", id)
+		return apperrors.New(apperrors.ErrNotFound, "no playlist found with id %d", id)
+	}
+
+	return nil
+}
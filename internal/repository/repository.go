@@ -1,12 +1,18 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"log/slog"
+	"music-library/internal/apperrors"
+	"music-library/internal/metrics"
 	"music-library/internal/models"
+	"strings"
+	"time"
 
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 )
 
 type SongRepository struct {
@@ -19,102 +25,262 @@ func NewSongRepository(db *sql.DB) *SongRepository {
 	}
 }
 
+// songColumns are the songs columns read by scanSong, in order.
+const songColumns = `id, group_name, song_name, release_date, text, link, album_id, disc_number, track_number, version, language::text, deleted_at`
+
+// scanSong reads a row selected with songColumns, followed by the extra columns, if any.
+func scanSong(row interface{ Scan(...interface{}) error }, extra ...interface{}) (*models.Song, error) {
+	var (
+		song        models.Song
+		releaseDate sql.NullString
+		albumID     sql.NullInt64
+		disc, track sql.NullInt32
+		deletedAt   sql.NullTime
+	)
+	dest := []interface{}{&song.ID, &song.GroupName, &song.SongName, &releaseDate, &song.Text, &song.Link, &albumID, &disc, &track, &song.Version, &song.Language, &deletedAt}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+
+	song.ReleaseDate = releaseDate.String
+	if albumID.Valid {
+		song.AlbumID = &albumID.Int64
+		song.DiscNumber = int(disc.Int32)
+		song.TrackNumber = int(track.Int32)
+	}
+	if deletedAt.Valid {
+		song.DeletedAt = &deletedAt.Time
+	}
+	return &song, nil
+}
+
 func (r *SongRepository) Close() error {
 	slog.Info("Closing database connection")
 	return r.db.Close()
 }
 
-func (r *SongRepository) AddSongRepository(song models.Song) error {
-	query := `INSERT INTO songs (group_name, song_name, release_date, text, link) VALUES ($1, $2, $3, $4, $5)`
+// AddSongRepository inserts the song, credits its group as the primary artist and records the insert revision.
+func (r *SongRepository) AddSongRepository(ctx context.Context, song models.Song) error {
+	defer metrics.ObserveQuery("AddSongRepository", time.Now())
 
-	_, err := r.db.Exec(query, song.GroupName, song.SongName, song.ReleaseDate, song.Text, song.Link)
+	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
+		slog.Error("Failed to begin transaction", "error", err)
+		return translateError(ctx, err, "failed to begin transaction")
+	}
+	defer tx.Rollback()
+
+	if err := insertSong(ctx, tx, &song); err != nil {
 		slog.Error("Failed to add song", "group_name", song.GroupName, "song_name", song.SongName, "error", err)
-		return fmt.Errorf("failed to add song: %w", err)
+		return err
+	}
+
+	after, err := readSong(ctx, tx, song.ID)
+	if err != nil {
+		return err
+	}
+	if err := recordRevision(ctx, tx, song.ID, models.RevisionInsert, nil, nil, after); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		slog.Error("Failed to commit song", "group_name", song.GroupName, "song_name", song.SongName, "error", err)
+		return translateError(ctx, err, "failed to add song")
 	}
 
 	slog.Info("Song added successfully", "group_name", song.GroupName, "song_name", song.SongName)
 	return nil
 }
 
-func (r *SongRepository) GetSongRepository(id string) (*models.Song, error) {
-	query := `SELECT id, group_name, song_name, release_date, text, link FROM songs WHERE id = $1`
+// insertSong inserts the song with its lyrics sections and credits its group as the primary artist.
+func insertSong(ctx context.Context, tx *sql.Tx, song *models.Song) error {
+	query := `INSERT INTO songs (id, group_name, song_name, release_date, text, link, language)
+	          VALUES ($1, $2, $3, NULLIF($4, '')::date, $5, $6, COALESCE(NULLIF($7, ''), 'simple')::regconfig)`
 
-	var song models.Song
-	err := r.db.QueryRow(query, id).Scan(&song.ID, &song.GroupName, &song.SongName, &song.ReleaseDate, &song.Text, &song.Link)
+	if _, err := tx.ExecContext(ctx, query, song.ID, song.GroupName, song.SongName, song.ReleaseDate, song.Text, song.Link, song.Language); err != nil {
+		return translateError(ctx, err, "failed to add song")
+	}
+	if err := replaceSongSections(ctx, tx, song.ID, song.Text); err != nil {
+		return err
+	}
+
+	return setPrimaryArtist(ctx, tx, song.ID, song.GroupName)
+}
+
+func (r *SongRepository) GetSongRepository(ctx context.Context, id string) (*models.Song, error) {
+	defer metrics.ObserveQuery("GetSongRepository", time.Now())
+
+	query := `SELECT ` + songColumns + ` FROM songs WHERE id = $1 AND deleted_at IS NULL`
+
+	song, err := scanSong(r.db.QueryRowContext(ctx, query, id))
 	if err == sql.ErrNoRows {
 		slog.Warn("No song found", "id", id)
-		return nil, fmt.Errorf("no song found with id %s", id)
+		return nil, apperrors.New(apperrors.ErrNotFound, "no song found with id %s", id)
 	} else if err != nil {
 		slog.Error("Failed to execute query", "id", id, "error", err)
-		return nil, fmt.Errorf("failed to execute query: %w", err)
+		return nil, translateError(ctx, err, "failed to execute query")
 	}
 
 	slog.Info("Song retrieved successfully", "id", id)
-	return &song, nil
+	return song, nil
 }
 
-func (r *SongRepository) GetAllSongsRepository() ([]*models.Song, error) {
-	query := `SELECT id, group_name, song_name, release_date, text, link FROM songs`
+// GetSongByName returns the song of the group with the given name, which is unique outside the trash.
+func (r *SongRepository) GetSongByName(ctx context.Context, group, song string) (*models.Song, error) {
+	defer metrics.ObserveQuery("GetSongByName", time.Now())
+
+	query := `SELECT ` + songColumns + ` FROM songs WHERE group_name = $1 AND song_name = $2 AND deleted_at IS NULL`
 
-	rows, err := r.db.Query(query)
+	found, err := scanSong(r.db.QueryRowContext(ctx, query, group, song))
+	if err == sql.ErrNoRows {
+		slog.Warn("No song found", "group_name", group, "song_name", song)
+		return nil, apperrors.New(apperrors.ErrNotFound, "no song %q by %q found", song, group)
+	} else if err != nil {
+		slog.Error("Failed to execute query", "group_name", group, "song_name", song, "error", err)
+		return nil, translateError(ctx, err, "failed to execute query")
+	}
+
+	return found, nil
+}
+
+func (r *SongRepository) SongExists(ctx context.Context, group, song string) (bool, error) {
+	defer metrics.ObserveQuery("SongExists", time.Now())
+
+	query := `SELECT EXISTS (SELECT 1 FROM songs WHERE group_name = $1 AND song_name = $2 AND deleted_at IS NULL)`
+
+	var exists bool
+	if err := r.db.QueryRowContext(ctx, query, group, song).Scan(&exists); err != nil {
+		slog.Error("Failed to check song existence", "group_name", group, "song_name", song, "error", err)
+		return false, translateError(ctx, err, "failed to execute query")
+	}
+
+	return exists, nil
+}
+
+func (r *SongRepository) GetAllSongsRepository(ctx context.Context) ([]*models.Song, error) {
+	defer metrics.ObserveQuery("GetAllSongsRepository", time.Now())
+
+	query := `SELECT ` + songColumns + ` FROM songs WHERE deleted_at IS NULL`
+
+	rows, err := r.db.QueryContext(ctx, query)
 	if err != nil {
 		slog.Error("Failed to execute query for all songs", "error", err)
-		return nil, fmt.Errorf("failed to execute query: %w", err)
+		return nil, translateError(ctx, err, "failed to execute query")
 	}
 	defer rows.Close()
 
 	var songs []*models.Song
 	for rows.Next() {
-		var song models.Song
-		err := rows.Scan(&song.ID, &song.GroupName, &song.SongName, &song.ReleaseDate, &song.Text, &song.Link)
+		song, err := scanSong(rows)
 		if err != nil {
 			slog.Error("Failed to scan song row", "error", err)
 			return nil, fmt.Errorf("failed to scan song row: %w", err)
 		}
-		songs = append(songs, &song)
+		songs = append(songs, song)
 	}
 
 	if err := rows.Err(); err != nil {
 		slog.Error("Error iterating over rows", "error", err)
-		return nil, fmt.Errorf("error iterating over rows: %w", err)
+		return nil, translateError(ctx, err, "error iterating over rows")
 	}
 
 	slog.Info("Retrieved all songs successfully", "count", len(songs))
 	return songs, nil
 }
 
-func (r *SongRepository) UpdateSongRepository(id string, song *models.Song) error {
-	query := `UPDATE songs SET group_name = $1, song_name = $2, release_date = $3, text = $4, link = $5 WHERE id = $6`
+// UpdateSongRepository updates the song, bumps its version, moves its primary credit to the artist
+// named by its group and records the update revision. Without a release date the song inherits the
+// date of its album, if any. A positive expectedVersion must match the current version, otherwise
+// ErrPrecondition is returned.
+func (r *SongRepository) UpdateSongRepository(ctx context.Context, id string, song *models.Song, expectedVersion int) error {
+	defer metrics.ObserveQuery("UpdateSongRepository", time.Now())
 
-	result, err := r.db.Exec(query, song.GroupName, song.SongName, song.ReleaseDate, song.Text, song.Link, id)
+	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
+		slog.Error("Failed to begin transaction", "error", err)
+		return translateError(ctx, err, "failed to begin transaction")
+	}
+	defer tx.Rollback()
+
+	before, err := lockSong(ctx, tx, id, expectedVersion)
+	if err != nil {
+		return err
+	}
+
+	if err := updateSong(ctx, tx, id, song); err != nil {
 		slog.Error("Failed to update song", "id", id, "error", err)
-		return fmt.Errorf("failed to update song with id %s: %w", id, err)
+		return err
 	}
 
-	if err := checkRowsAffected(result, id); err != nil {
+	after, err := readSong(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+	if err := recordRevision(ctx, tx, id, models.RevisionUpdate, nil, before, after); err != nil {
 		return err
 	}
 
+	if err := tx.Commit(); err != nil {
+		slog.Error("Failed to commit song update", "id", id, "error", err)
+		return translateError(ctx, err, fmt.Sprintf("failed to update song with id %s", id))
+	}
+
 	slog.Info("Song updated successfully", "id", id)
 	return nil
 }
 
-func (r *SongRepository) DeleteSongRepository(id string) error {
-	query := `DELETE FROM songs WHERE id = $1`
+// updateSong updates the song and its lyrics sections, bumps its version and moves its primary credit to the artist named by its group.
+// Without a language the song keeps its current one.
+func updateSong(ctx context.Context, tx *sql.Tx, id string, song *models.Song) error {
+	query := `UPDATE songs SET group_name = $1, song_name = $2,
+	                 release_date = COALESCE(NULLIF($3, '')::date, (SELECT release_date FROM albums WHERE id = songs.album_id)),
+	                 text = $4, link = $5, language = COALESCE(NULLIF($6, '')::regconfig, language), version = version + 1
+	          WHERE id = $7`
 
-	result, err := r.db.Exec(query, id)
+	if _, err := tx.ExecContext(ctx, query, song.GroupName, song.SongName, song.ReleaseDate, song.Text, song.Link, song.Language, id); err != nil {
+		return translateError(ctx, err, fmt.Sprintf("failed to update song with id %s", id))
+	}
+	if err := replaceSongSections(ctx, tx, id, song.Text); err != nil {
+		return err
+	}
+
+	return setPrimaryArtist(ctx, tx, id, song.GroupName)
+}
+
+// DeleteSongRepository moves the song to the trash and records the delete revision. The row is kept
+// until it is restored or purged. A positive expectedVersion must match the current version,
+// otherwise ErrPrecondition is returned.
+func (r *SongRepository) DeleteSongRepository(ctx context.Context, id string, expectedVersion int) error {
+	defer metrics.ObserveQuery("DeleteSongRepository", time.Now())
+
+	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
+		slog.Error("Failed to begin transaction", "error", err)
+		return translateError(ctx, err, "failed to begin transaction")
+	}
+	defer tx.Rollback()
+
+	before, err := lockSong(ctx, tx, id, expectedVersion)
+	if err != nil {
+		return err
+	}
+
+	query := `UPDATE songs SET deleted_at = now(), version = version + 1 WHERE id = $1`
+	if _, err := tx.ExecContext(ctx, query, id); err != nil {
 		slog.Error("Failed to delete song", "id", id, "error", err)
-		return fmt.Errorf("failed to delete song with id %s: %w", id, err)
+		return translateError(ctx, err, fmt.Sprintf("failed to delete song with id %s", id))
 	}
 
-	if err := checkRowsAffected(result, id); err != nil {
+	if err := recordRevision(ctx, tx, id, models.RevisionDelete, nil, before, nil); err != nil {
 		return err
 	}
 
-	slog.Info("Song deleted successfully", "id", id)
+	if err := tx.Commit(); err != nil {
+		slog.Error("Failed to commit song deletion", "id", id, "error", err)
+		return translateError(ctx, err, fmt.Sprintf("failed to delete song with id %s", id))
+	}
+
+	slog.Info("Song moved to trash", "id", id)
 	return nil
 }
 
@@ -127,74 +293,346 @@ func checkRowsAffected(result sql.Result, id string) error {
 
 	if rowsAffected == 0 {
 		slog.Warn("No rows affected", "id", id)
-		return fmt.Errorf("no song found with id %s", id)
+		return apperrors.New(apperrors.ErrNotFound, "no song found with id %s", id)
 	}
 
 	slog.Info("Rows affected", "id", id, "rows_affected", rowsAffected)
 	return nil
 }
 
-func (r *SongRepository) GetSongPaginated(filter map[string]string, page, pageSize int) ([]*models.Song, error) {
-	query := `SELECT id, group_name, song_name, text, link, release_date 
-	          FROM songs WHERE 1=1`
+// exportBatchSize is the number of rows fetched from the export cursor at a time.
+const exportBatchSize = 500
+
+// songFilterClause turns list filters into SQL conditions appended to a WHERE clause. With a search
+// query it also returns the tsquery expression, for ranking and highlighting; it is empty otherwise.
+func songFilterClause(filter *models.SongFilter) (string, []interface{}, string) {
+	clause := ""
 	args := []interface{}{}
-	argID := 1
+	tsquery := ""
+
+	for _, names := range []struct {
+		column string
+		values []string
+	}{{"group_name", filter.Groups}, {"song_name", filter.Songs}} {
+		if len(names.values) == 0 {
+			continue
+		}
+		if filter.Match == models.MatchExact {
+			args = append(args, pq.Array(names.values))
+			clause += fmt.Sprintf(" AND %s = ANY($%d)", names.column, len(args))
+			continue
+		}
+		patterns := make([]string, len(names.values))
+		for i, value := range names.values {
+			patterns[i] = "%" + likeEscaper.Replace(value) + "%"
+		}
+		args = append(args, pq.Array(patterns))
+		clause += fmt.Sprintf(" AND %s ILIKE ANY($%d)", names.column, len(args))
+	}
+	if filter.ReleasedAfter != "" {
+		args = append(args, filter.ReleasedAfter)
+		clause += fmt.Sprintf(" AND release_date >= $%d::date", len(args))
+	}
+	if filter.ReleasedBefore != "" {
+		args = append(args, filter.ReleasedBefore)
+		clause += fmt.Sprintf(" AND release_date <= $%d::date", len(args))
+	}
+	if filter.Q != "" {
+		tsquery, args = searchQuery(filter.Q, filter.Languages, args)
+		clause += " AND search_vector @@ " + tsquery
+	}
+
+	return clause, args, tsquery
+}
 
-	if group, ok := filter["group"]; ok {
-		query += fmt.Sprintf(" AND group_name ILIKE $%d", argID)
-		args = append(args, "%"+group+"%")
-		argID++
+// likeEscaper escapes the wildcards of a LIKE pattern, so that values match literally.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// songSortColumns are the columns of the sort fields.
+var songSortColumns = map[string]string{
+	"release_date": "release_date",
+	"group_name":   "group_name",
+	"song_name":    "song_name",
+	"id":           "id",
+}
+
+// songOrder returns the ORDER BY list of the filter's sort, or def without one. Undated songs come
+// last either way, and the song ID breaks ties.
+func songOrder(filter *models.SongFilter, def string) string {
+	if len(filter.Sort) == 0 {
+		return def
 	}
-	if song, ok := filter["song"]; ok {
-		query += fmt.Sprintf(" AND song_name ILIKE $%d", argID)
-		args = append(args, "%"+song+"%")
-		argID++
+
+	keys := make([]string, 0, len(filter.Sort)+1)
+	byID := false
+	for _, s := range filter.Sort {
+		direction := "ASC"
+		if s.Desc {
+			direction = "DESC"
+		}
+		keys = append(keys, songSortColumns[s.Field]+" "+direction+" NULLS LAST")
+		byID = byID || s.Field == "id"
 	}
-	if text, ok := filter["text"]; ok {
-		query += fmt.Sprintf(" AND to_tsvector('russian', text) @@ plainto_tsquery('russian', $%d)", argID)
-		args = append(args, text)
-		argID++
+	if !byID {
+		keys = append(keys, "id DESC")
 	}
+	return strings.Join(keys, ", ")
+}
+
+// songListColumns are the songColumns of a list. The lyrics are only read when the filter asks for them.
+func songListColumns(filter *models.SongFilter) string {
+	if filter.Wants("text") {
+		return songColumns
+	}
+	return strings.Replace(songColumns, ", text, ", ", ''::text AS text, ", 1)
+}
+
+// searchQuery returns a tsquery expression matching q in any of the text search configurations,
+// so that songs are found whatever their language, and the args extended with its parameters.
+// The expression is constant for a query, which lets idx_songs_search serve it.
+func searchQuery(q string, languages []string, args []interface{}) (string, []interface{}) {
+	args = append(args, q)
+	qArg := len(args)
+
+	parts := make([]string, 0, len(languages))
+	for _, language := range languages {
+		args = append(args, language)
+		parts = append(parts, fmt.Sprintf("websearch_to_tsquery($%d::regconfig, $%d)", len(args), qArg))
+	}
+	return "(" + strings.Join(parts, " || ") + ")", args
+}
+
+// Matched terms are delimited with private-use characters, which no lyrics contain, so that the
+// rest of the headline can be HTML-escaped before they become <mark> tags.
+const (
+	highlightStart = "\uE000"
+	highlightStop  = "\uE001"
+)
 
-	query += fmt.Sprintf(" ORDER BY release_date DESC LIMIT $%d OFFSET $%d", argID, argID+1)
+// highlightSel selects the matched terms of a headline; nameHeadlineOptions configure the headlines
+// of names and headlineOptions the lyrics snippets of search results.
+const (
+	highlightSel        = `StartSel="` + highlightStart + `", StopSel="` + highlightStop + `"`
+	nameHeadlineOptions = `HighlightAll=true, ` + highlightSel
+	headlineOptions     = highlightSel + `, MaxFragments=3, MaxWords=20, MinWords=5, FragmentDelimiter=" … "`
+)
+
+// highlightReplacer HTML-escapes a headline and marks its matched terms.
+var highlightReplacer = strings.NewReplacer(
+	"&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&#34;", "'", "&#39;",
+	highlightStart, "<mark>", highlightStop, "</mark>",
+)
+
+// GetSongPaginated returns a page of the songs matching filter in the filter's order, or in list
+// order by default. Search results are ordered by rank by default and carry their rank and highlighted fields.
+func (r *SongRepository) GetSongPaginated(ctx context.Context, filter *models.SongFilter, page, pageSize int) ([]*models.Song, error) {
+	defer metrics.ObserveQuery("GetSongPaginated", time.Now())
+
+	clause, args, tsquery := songFilterClause(filter)
 	args = append(args, pageSize, (page-1)*pageSize)
+	limit := fmt.Sprintf(" LIMIT $%d OFFSET $%d", len(args)-1, len(args))
+	columns := songListColumns(filter)
+
+	query := `SELECT ` + columns + ` FROM songs WHERE deleted_at IS NULL` + clause +
+		` ORDER BY ` + songOrder(filter, songSortKey+` DESC, id DESC`) + limit
+	if tsquery != "" {
+		// Highlighting is costly, so it is only done for the rows of the page.
+		order := ` ORDER BY ` + songOrder(filter, `rank DESC, `+songSortKey+` DESC, id DESC`)
+		query = `SELECT ` + columns + `, rank,
+		                ts_headline(language, song_name, ` + tsquery + `, '` + nameHeadlineOptions + `'),
+		                ts_headline(language, group_name, ` + tsquery + `, '` + nameHeadlineOptions + `'),
+		                ts_headline(language, text, ` + tsquery + `, '` + headlineOptions + `')
+		         FROM (SELECT *, ts_rank(search_vector, ` + tsquery + `) AS rank
+		               FROM songs WHERE deleted_at IS NULL` + clause + order + limit + `) songs` + order
+	}
 
-	rows, err := r.db.Query(query, args...)
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
-		return nil, err
+		slog.Error("Failed to execute paginated query", "error", err)
+		return nil, translateError(ctx, err, "failed to fetch songs")
 	}
 	defer rows.Close()
 
 	var songs []*models.Song
 	for rows.Next() {
-		var song models.Song
-		if err := rows.Scan(&song.ID, &song.GroupName, &song.SongName, &song.Text, &song.Link, &song.ReleaseDate); err != nil {
+		var (
+			song *models.Song
+			err  error
+		)
+		if tsquery != "" {
+			var rank float64
+			var highlight models.SongHighlight
+			song, err = scanSong(rows, &rank, &highlight.SongName, &highlight.GroupName, &highlight.Text)
+			if song != nil {
+				for _, field := range []*string{&highlight.SongName, &highlight.GroupName, &highlight.Text} {
+					*field = highlightReplacer.Replace(*field)
+				}
+				song.Rank, song.Highlight = rank, &highlight
+			}
+		} else {
+			song, err = scanSong(rows)
+		}
+		if err != nil {
 			return nil, err
 		}
-		songs = append(songs, &song)
+		songs = append(songs, song)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, translateError(ctx, err, "error iterating over rows")
 	}
 
 	return songs, nil
 }
 
-func (r *SongRepository) GetSongTextPaginated(id string, page, pageSize int) ([]string, error) {
-	query := `SELECT unnest(string_to_array(text, E'\n\n')) AS verse 
-	          FROM songs WHERE id = $1 LIMIT $2 OFFSET $3`
+// CountSongs returns the number of songs matching filter.
+func (r *SongRepository) CountSongs(ctx context.Context, filter *models.SongFilter) (int64, error) {
+	defer metrics.ObserveQuery("CountSongs", time.Now())
+
+	clause, args, _ := songFilterClause(filter)
+
+	var count int64
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM songs WHERE deleted_at IS NULL`+clause, args...).Scan(&count); err != nil {
+		slog.Error("Failed to count songs", "error", err)
+		return 0, translateError(ctx, err, "failed to count songs")
+	}
+
+	return count, nil
+}
+
+// EstimateSongs returns the query planner's estimate of the number of songs matching filter.
+// The songs are not read, so the cost does not grow with the table, but the estimate is only as
+// good as the table statistics.
+func (r *SongRepository) EstimateSongs(ctx context.Context, filter *models.SongFilter) (int64, error) {
+	defer metrics.ObserveQuery("EstimateSongs", time.Now())
+
+	clause, args, _ := songFilterClause(filter)
+
+	var plan []byte
+	if err := r.db.QueryRowContext(ctx, `EXPLAIN (FORMAT JSON) SELECT 1 FROM songs WHERE deleted_at IS NULL`+clause, args...).Scan(&plan); err != nil {
+		slog.Error("Failed to estimate songs", "error", err)
+		return 0, translateError(ctx, err, "failed to estimate songs")
+	}
+
+	var explained []struct {
+		Plan struct {
+			Rows float64 `json:"Plan Rows"`
+		} `json:"Plan"`
+	}
+	if err := json.Unmarshal(plan, &explained); err != nil {
+		return 0, fmt.Errorf("failed to decode query plan: %w", err)
+	}
+	if len(explained) == 0 {
+		return 0, fmt.Errorf("empty query plan")
+	}
+
+	return int64(explained[0].Plan.Rows), nil
+}
+
+// songSortKey orders the song list by release date, newest first, with undated songs last.
+// It matches the expression of idx_songs_release_id; the song ID breaks ties.
+const songSortKey = `COALESCE(release_date, '-infinity'::date)`
+
+// GetSongsByCursor returns up to limit songs matching filter in list order, starting after the
+// cursor position, or from the top without a cursor. With a backward cursor the songs before the
+// position are returned, nearest first.
+// The filter's sort is not applied, as cursors only mark positions in list order.
+func (r *SongRepository) GetSongsByCursor(ctx context.Context, filter *models.SongFilter, cursor *models.SongCursor, limit int) ([]*models.Song, error) {
+	defer metrics.ObserveQuery("GetSongsByCursor", time.Now())
+
+	clause, args, _ := songFilterClause(filter)
+	query := `SELECT ` + songListColumns(filter) + ` FROM songs WHERE deleted_at IS NULL` + clause
+
+	order := "DESC"
+	if cursor != nil {
+		comparison := "<"
+		if cursor.Backward {
+			comparison, order = ">", "ASC"
+		}
+		args = append(args, cursor.ReleaseDate, cursor.ID)
+		query += fmt.Sprintf(" AND ("+songSortKey+", id) %s (COALESCE(NULLIF($%d, '')::date, '-infinity'::date), $%d)", comparison, len(args)-1, len(args))
+	}
+
+	args = append(args, limit)
+	query += fmt.Sprintf(" ORDER BY %s %s, id %s LIMIT $%d", songSortKey, order, order, len(args))
 
-	rows, err := r.db.Query(query, id, pageSize, (page-1)*pageSize)
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
-		return nil, err
+		slog.Error("Failed to execute cursor query", "error", err)
+		return nil, translateError(ctx, err, "failed to fetch songs")
 	}
 	defer rows.Close()
 
-	var verses []string
+	songs := []*models.Song{}
 	for rows.Next() {
-		var verse string
-		if err := rows.Scan(&verse); err != nil {
-			return nil, err
+		song, err := scanSong(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan song row: %w", err)
+		}
+		songs = append(songs, song)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, translateError(ctx, err, "error iterating over rows")
+	}
+
+	return songs, nil
+}
+
+// ExportSongs streams every song matching filter to fn through a server-side cursor,
+// so only one batch of rows is held in memory at a time. Iteration stops at the first error returned by fn.
+func (r *SongRepository) ExportSongs(ctx context.Context, filter *models.SongFilter, fn func(*models.Song) error) error {
+	defer metrics.ObserveQuery("ExportSongs", time.Now())
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		slog.Error("Failed to begin export transaction", "error", err)
+		return translateError(ctx, err, "failed to begin transaction")
+	}
+	defer tx.Rollback()
+
+	clause, args, _ := songFilterClause(filter)
+	declare := `DECLARE song_export NO SCROLL CURSOR FOR
+	            SELECT ` + songColumns + `
+	            FROM songs WHERE deleted_at IS NULL` + clause + ` ORDER BY ` + songOrder(filter, songSortKey+` DESC, id DESC`)
+
+	if _, err := tx.ExecContext(ctx, declare, args...); err != nil {
+		slog.Error("Failed to declare export cursor", "error", err)
+		return translateError(ctx, err, "failed to declare cursor")
+	}
+
+	fetch := fmt.Sprintf("FETCH FORWARD %d FROM song_export", exportBatchSize)
+	total := 0
+	for {
+		rows, err := tx.QueryContext(ctx, fetch)
+		if err != nil {
+			slog.Error("Failed to fetch from export cursor", "error", err)
+			return translateError(ctx, err, "failed to fetch songs")
+		}
+
+		fetched := 0
+		for rows.Next() {
+			song, err := scanSong(rows)
+			if err != nil {
+				rows.Close()
+				return fmt.Errorf("failed to scan song row: %w", err)
+			}
+			fetched++
+			if err := fn(song); err != nil {
+				rows.Close()
+				return err
+			}
+		}
+		if err := rows.Err(); err != nil {
+			rows.Close()
+			return translateError(ctx, err, "error iterating over rows")
+		}
+		rows.Close()
+
+		total += fetched
+		if fetched < exportBatchSize {
+			break
 		}
-		verses = append(verses, verse)
 	}
 
-	return verses, nil
+	slog.Info("Songs exported", "count", total)
+	return tx.Commit()
 }
@@ -0,0 +1,271 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"music-library/internal/apperrors"
+	"music-library/internal/auth"
+	"music-library/internal/metrics"
+	"music-library/internal/models"
+	"time"
+)
+
+// revisionColumns are the song_revisions columns read by scanRevision, in order.
+const revisionColumns = `song_id, revision, action, actor, source_revision, before, after, created_at`
+
+func scanRevision(row interface{ Scan(...interface{}) error }) (*models.SongRevision, error) {
+	var (
+		revision      models.SongRevision
+		source        sql.NullInt32
+		before, after []byte
+	)
+	if err := row.Scan(&revision.SongID, &revision.Revision, &revision.Action, &revision.Actor, &source, &before, &after, &revision.CreatedAt); err != nil {
+		return nil, err
+	}
+
+	if source.Valid {
+		n := int(source.Int32)
+		revision.SourceRevision = &n
+	}
+	for _, s := range []struct {
+		data   []byte
+		target **models.Song
+	}{{before, &revision.Before}, {after, &revision.After}} {
+		if s.data == nil {
+			continue
+		}
+		if err := json.Unmarshal(s.data, s.target); err != nil {
+			return nil, fmt.Errorf("failed to decode revision snapshot: %w", err)
+		}
+	}
+	return &revision, nil
+}
+
+// songActor returns the subject of the credential of the request, or "system" for changes
+// made without one, such as background jobs.
+func songActor(ctx context.Context) string {
+	if principal, ok := auth.FromContext(ctx); ok {
+		return principal.Subject
+	}
+	return "system"
+}
+
+// readSong reads the song within tx.
+func readSong(ctx context.Context, tx *sql.Tx, id string) (*models.Song, error) {
+	song, err := scanSong(tx.QueryRowContext(ctx, `SELECT `+songColumns+` FROM songs WHERE id = $1`, id))
+	if err != nil {
+		return nil, translateError(ctx, err, "failed to read song")
+	}
+	return song, nil
+}
+
+// lockSong reads the song and locks it until tx ends. Songs in the trash are not found.
+// A positive expectedVersion must match the song's version, otherwise ErrPrecondition is returned.
+func lockSong(ctx context.Context, tx *sql.Tx, id string, expectedVersion int) (*models.Song, error) {
+	song, err := lockSongRow(ctx, tx, id, expectedVersion)
+	if err == nil && song.DeletedAt != nil {
+		slog.Warn("Song is in the trash", "id", id)
+		return nil, apperrors.New(apperrors.ErrNotFound, "no song found with id %s", id)
+	}
+	return song, err
+}
+
+// lockSongRow is lockSong that also finds songs in the trash.
+func lockSongRow(ctx context.Context, tx *sql.Tx, id string, expectedVersion int) (*models.Song, error) {
+	song, err := scanSong(tx.QueryRowContext(ctx, `SELECT `+songColumns+` FROM songs WHERE id = $1 FOR UPDATE`, id))
+	if err == sql.ErrNoRows {
+		slog.Warn("No song found", "id", id)
+		return nil, apperrors.New(apperrors.ErrNotFound, "no song found with id %s", id)
+	} else if err != nil {
+		return nil, translateError(ctx, err, "failed to read song")
+	}
+
+	if expectedVersion > 0 && song.Version != expectedVersion {
+		slog.Warn("Song version mismatch", "id", id, "version", song.Version, "expected", expectedVersion)
+		return nil, apperrors.New(apperrors.ErrPrecondition, "song %s was modified: version is %d, not %d", id, song.Version, expectedVersion)
+	}
+	return song, nil
+}
+
+// lockSongs reads and locks, until tx ends, the songs matching where, a condition on songs
+// that may use args. Together with recordSongUpdates it records the revisions of changes
+// that reach songs through their artist or album.
+func lockSongs(ctx context.Context, tx *sql.Tx, where string, args ...interface{}) ([]*models.Song, error) {
+	rows, err := tx.QueryContext(ctx, `SELECT `+songColumns+` FROM songs WHERE `+where+` ORDER BY id FOR UPDATE`, args...)
+	if err != nil {
+		slog.Error("Failed to lock songs", "error", err)
+		return nil, translateError(ctx, err, "failed to read songs")
+	}
+	defer rows.Close()
+
+	var songs []*models.Song
+	for rows.Next() {
+		song, err := scanSong(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan song row: %w", err)
+		}
+		songs = append(songs, song)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, translateError(ctx, err, "error iterating over rows")
+	}
+	return songs, nil
+}
+
+// recordSongUpdates records an update revision for each of the songs locked by lockSongs
+// whose version has changed since.
+func recordSongUpdates(ctx context.Context, tx *sql.Tx, before []*models.Song) error {
+	for _, song := range before {
+		after, err := readSong(ctx, tx, song.ID)
+		if err != nil {
+			return err
+		}
+		if after.Version == song.Version {
+			continue
+		}
+		if err := recordRevision(ctx, tx, song.ID, models.RevisionUpdate, nil, song, after); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// recordRevision appends a revision with the before and after snapshots to the song's audit log.
+func recordRevision(ctx context.Context, tx *sql.Tx, songID string, action models.RevisionAction, source *int, before, after *models.Song) error {
+	snapshots := make([]interface{}, 2)
+	for i, song := range []*models.Song{before, after} {
+		if song == nil {
+			continue
+		}
+		data, err := json.Marshal(song)
+		if err != nil {
+			return fmt.Errorf("failed to encode revision snapshot: %w", err)
+		}
+		snapshots[i] = string(data)
+	}
+
+	query := `INSERT INTO song_revisions (song_id, revision, action, actor, source_revision, before, after)
+	          SELECT $1, COALESCE(MAX(revision), 0) + 1, $2, $3, $4, $5, $6 FROM song_revisions WHERE song_id = $1`
+
+	if _, err := tx.ExecContext(ctx, query, songID, action, songActor(ctx), source, snapshots[0], snapshots[1]); err != nil {
+		slog.Error("Failed to record song revision", "song_id", songID, "action", action, "error", err)
+		return translateError(ctx, err, "failed to record song revision")
+	}
+	return nil
+}
+
+// GetSongRevisions returns a page of the song's revisions, newest first. Revisions are kept after
+// the song is deleted; a song without any is reported as not found.
+func (r *SongRepository) GetSongRevisions(ctx context.Context, id string, page, pageSize int) ([]*models.SongRevision, error) {
+	defer metrics.ObserveQuery("GetSongRevisions", time.Now())
+
+	query := `SELECT ` + revisionColumns + ` FROM song_revisions WHERE song_id = $1 ORDER BY revision DESC LIMIT $2 OFFSET $3`
+
+	rows, err := r.db.QueryContext(ctx, query, id, pageSize, (page-1)*pageSize)
+	if err != nil {
+		slog.Error("Failed to fetch song revisions", "id", id, "error", err)
+		return nil, translateError(ctx, err, "failed to fetch song revisions")
+	}
+	defer rows.Close()
+
+	revisions := []*models.SongRevision{}
+	for rows.Next() {
+		revision, err := scanRevision(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan revision row: %w", err)
+		}
+		revisions = append(revisions, revision)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, translateError(ctx, err, "error iterating over rows")
+	}
+
+	if len(revisions) == 0 && page == 1 {
+		return nil, apperrors.New(apperrors.ErrNotFound, "no history found for song with id %s", id)
+	}
+	return revisions, nil
+}
+
+// GetSongRevision returns a single revision of the song.
+func (r *SongRepository) GetSongRevision(ctx context.Context, id string, revision int) (*models.SongRevision, error) {
+	defer metrics.ObserveQuery("GetSongRevision", time.Now())
+
+	return getSongRevision(ctx, r.db, id, revision)
+}
+
+func getSongRevision(ctx context.Context, q interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}, id string, revision int) (*models.SongRevision, error) {
+	query := `SELECT ` + revisionColumns + ` FROM song_revisions WHERE song_id = $1 AND revision = $2`
+
+	result, err := scanRevision(q.QueryRowContext(ctx, query, id, revision))
+	if err == sql.ErrNoRows {
+		return nil, apperrors.New(apperrors.ErrNotFound, "song %s has no revision %d", id, revision)
+	} else if err != nil {
+		slog.Error("Failed to fetch song revision", "id", id, "revision", revision, "error", err)
+		return nil, translateError(ctx, err, "failed to fetch song revision")
+	}
+	return result, nil
+}
+
+// RevertSongRepository restores the details the song had after the given revision and records a
+// revert revision. A song in the trash is restored and a purged song is recreated. Album placement and credits other than the primary
+// artist are left as they are. A positive expectedVersion must match the current version.
+func (r *SongRepository) RevertSongRepository(ctx context.Context, id string, revision, expectedVersion int) error {
+	defer metrics.ObserveQuery("RevertSongRepository", time.Now())
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		slog.Error("Failed to begin transaction", "error", err)
+		return translateError(ctx, err, "failed to begin transaction")
+	}
+	defer tx.Rollback()
+
+	source, err := getSongRevision(ctx, tx, id, revision)
+	if err != nil {
+		return err
+	}
+	if source.After == nil {
+		return apperrors.New(apperrors.ErrValidation, "revision %d deleted song %s, revert to an earlier revision", revision, id)
+	}
+	target := *source.After
+	target.ID = id
+
+	before, err := lockSongRow(ctx, tx, id, expectedVersion)
+	switch {
+	case err == nil && before.DeletedAt != nil:
+		if err = restoreSong(ctx, tx, id); err == nil {
+			err = updateSong(ctx, tx, id, &target)
+		}
+	case err == nil:
+		err = updateSong(ctx, tx, id, &target)
+	case errors.Is(err, apperrors.ErrNotFound) && expectedVersion == 0:
+		err = insertSong(ctx, tx, &target)
+	case errors.Is(err, apperrors.ErrNotFound):
+		err = apperrors.New(apperrors.ErrPrecondition, "song %s was deleted", id)
+	}
+	if err != nil {
+		slog.Error("Failed to revert song", "id", id, "revision", revision, "error", err)
+		return err
+	}
+
+	after, err := readSong(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+	if err := recordRevision(ctx, tx, id, models.RevisionRevert, &revision, before, after); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		slog.Error("Failed to commit song revert", "id", id, "error", err)
+		return translateError(ctx, err, fmt.Sprintf("failed to revert song with id %s", id))
+	}
+
+	slog.Info("Song reverted successfully", "id", id, "revision", revision)
+	return nil
+}
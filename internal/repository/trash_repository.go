@@ -0,0 +1,143 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"music-library/internal/apperrors"
+	"music-library/internal/metrics"
+	"music-library/internal/models"
+	"time"
+)
+
+// GetTrash returns a page of the songs in the trash, most recently deleted first.
+func (r *SongRepository) GetTrash(ctx context.Context, page, pageSize int) ([]*models.Song, error) {
+	defer metrics.ObserveQuery("GetTrash", time.Now())
+
+	query := `SELECT ` + songColumns + ` FROM songs WHERE deleted_at IS NOT NULL ORDER BY deleted_at DESC, id LIMIT $1 OFFSET $2`
+
+	rows, err := r.db.QueryContext(ctx, query, pageSize, (page-1)*pageSize)
+	if err != nil {
+		slog.Error("Failed to fetch trash", "error", err)
+		return nil, translateError(ctx, err, "failed to fetch deleted songs")
+	}
+	defer rows.Close()
+
+	songs := []*models.Song{}
+	for rows.Next() {
+		song, err := scanSong(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan song row: %w", err)
+		}
+		songs = append(songs, song)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, translateError(ctx, err, "error iterating over rows")
+	}
+
+	return songs, nil
+}
+
+// RestoreSongRepository takes the song out of the trash and records the restore revision. A live song
+// with the same group and name makes the restore fail with ErrDuplicate. A positive expectedVersion
+// must match the current version.
+func (r *SongRepository) RestoreSongRepository(ctx context.Context, id string, expectedVersion int) error {
+	defer metrics.ObserveQuery("RestoreSongRepository", time.Now())
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		slog.Error("Failed to begin transaction", "error", err)
+		return translateError(ctx, err, "failed to begin transaction")
+	}
+	defer tx.Rollback()
+
+	before, err := lockSongRow(ctx, tx, id, expectedVersion)
+	if err != nil {
+		return err
+	}
+	if before.DeletedAt == nil {
+		return apperrors.New(apperrors.ErrNotFound, "song %s is not in the trash", id)
+	}
+
+	if err := restoreSong(ctx, tx, id); err != nil {
+		slog.Error("Failed to restore song", "id", id, "error", err)
+		return err
+	}
+
+	after, err := readSong(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+	if err := recordRevision(ctx, tx, id, models.RevisionRestore, nil, before, after); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		slog.Error("Failed to commit song restore", "id", id, "error", err)
+		return translateError(ctx, err, fmt.Sprintf("failed to restore song with id %s", id))
+	}
+
+	slog.Info("Song restored from trash", "id", id)
+	return nil
+}
+
+// restoreSong takes the song out of the trash and bumps its version.
+func restoreSong(ctx context.Context, tx *sql.Tx, id string) error {
+	query := `UPDATE songs SET deleted_at = NULL, version = version + 1 WHERE id = $1`
+	if _, err := tx.ExecContext(ctx, query, id); err != nil {
+		return translateError(ctx, err, fmt.Sprintf("failed to restore song with id %s", id))
+	}
+	return nil
+}
+
+// PurgeDeletedSongs permanently deletes up to limit songs that were moved to the trash before cutoff,
+// recording a purge revision for each, and returns how many were deleted. Rows locked by a concurrent
+// restore are skipped.
+func (r *SongRepository) PurgeDeletedSongs(ctx context.Context, cutoff time.Time, limit int) (int, error) {
+	defer metrics.ObserveQuery("PurgeDeletedSongs", time.Now())
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		slog.Error("Failed to begin transaction", "error", err)
+		return 0, translateError(ctx, err, "failed to begin transaction")
+	}
+	defer tx.Rollback()
+
+	query := `DELETE FROM songs WHERE id IN (
+	              SELECT id FROM songs WHERE deleted_at < $1 ORDER BY deleted_at LIMIT $2 FOR UPDATE SKIP LOCKED
+	          ) RETURNING ` + songColumns
+
+	rows, err := tx.QueryContext(ctx, query, cutoff, limit)
+	if err != nil {
+		slog.Error("Failed to purge deleted songs", "error", err)
+		return 0, translateError(ctx, err, "failed to purge deleted songs")
+	}
+
+	var purged []*models.Song
+	for rows.Next() {
+		song, err := scanSong(rows)
+		if err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan song row: %w", err)
+		}
+		purged = append(purged, song)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, translateError(ctx, err, "error iterating over rows")
+	}
+
+	for _, song := range purged {
+		if err := recordRevision(ctx, tx, song.ID, models.RevisionPurge, nil, song, nil); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		slog.Error("Failed to commit purge", "error", err)
+		return 0, translateError(ctx, err, "failed to purge deleted songs")
+	}
+
+	return len(purged), nil
+}
@@ -0,0 +1,234 @@
+// Package resilience implements retry backoff and circuit breaking for outbound calls.
+package resilience
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ErrBreakerOpen is returned while a circuit breaker rejects calls.
+var ErrBreakerOpen = errors.New("circuit breaker is open")
+
+// State is the state of a circuit breaker.
+type State int
+
+const (
+	StateClosed State = iota
+	StateOpen
+	StateHalfOpen
+)
+
+func (s State) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// BreakerConfig holds the thresholds of a circuit breaker.
+type BreakerConfig struct {
+	// FailureThreshold is the number of consecutive failures that opens the breaker.
+	FailureThreshold int
+	// OpenTimeout is how long the breaker stays open before letting trial calls through.
+	OpenTimeout time.Duration
+	// HalfOpenMaxCalls is the number of concurrent trial calls allowed while half-open.
+	HalfOpenMaxCalls int
+}
+
+// BreakerStatus is a snapshot of a circuit breaker.
+type BreakerStatus struct {
+	Name                string     `json:"name"`
+	State               string     `json:"state"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	OpenedAt            *time.Time `json:"opened_at,omitempty"`
+}
+
+// Breaker is a consecutive-failure circuit breaker.
+type Breaker struct {
+	name string
+	cfg  BreakerConfig
+	now  func() time.Time
+
+	mu       sync.Mutex
+	state    State
+	failures int
+	openedAt time.Time
+	trials   int
+}
+
+// NewBreaker creates a closed circuit breaker.
+func NewBreaker(name string, cfg BreakerConfig) *Breaker {
+	if cfg.FailureThreshold < 1 {
+		cfg.FailureThreshold = 1
+	}
+	if cfg.HalfOpenMaxCalls < 1 {
+		cfg.HalfOpenMaxCalls = 1
+	}
+
+	return &Breaker{
+		name: name,
+		cfg:  cfg,
+		now:  time.Now,
+	}
+}
+
+// Allow reports whether a call may proceed. Every allowed call must be followed
+// by exactly one Success, Failure or Ignore.
+func (b *Breaker) Allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == StateOpen {
+		if b.now().Sub(b.openedAt) < b.cfg.OpenTimeout {
+			return fmt.Errorf("%w for %s", ErrBreakerOpen, b.name)
+		}
+		b.setState(StateHalfOpen)
+	}
+
+	if b.state == StateHalfOpen {
+		if b.trials >= b.cfg.HalfOpenMaxCalls {
+			return fmt.Errorf("%w for %s", ErrBreakerOpen, b.name)
+		}
+		b.trials++
+	}
+
+	return nil
+}
+
+// Success records a successful call.
+func (b *Breaker) Success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures = 0
+	if b.state == StateHalfOpen {
+		b.trials--
+		b.setState(StateClosed)
+	}
+}
+
+// Failure records a failed call.
+func (b *Breaker) Failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	switch b.state {
+	case StateHalfOpen:
+		b.trials--
+		b.open()
+	case StateClosed:
+		if b.failures >= b.cfg.FailureThreshold {
+			b.open()
+		}
+	}
+}
+
+// Ignore releases an allowed call without recording an outcome, e.g. when the caller gave up.
+func (b *Breaker) Ignore() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == StateHalfOpen && b.trials > 0 {
+		b.trials--
+	}
+}
+
+// Status returns a snapshot of the breaker.
+func (b *Breaker) Status() BreakerStatus {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	status := BreakerStatus{
+		Name:                b.name,
+		State:               b.state.String(),
+		ConsecutiveFailures: b.failures,
+	}
+	if b.state != StateClosed {
+		openedAt := b.openedAt
+		status.OpenedAt = &openedAt
+	}
+
+	return status
+}
+
+func (b *Breaker) open() {
+	b.openedAt = b.now()
+	b.setState(StateOpen)
+}
+
+func (b *Breaker) setState(state State) {
+	if b.state == state {
+		return
+	}
+
+	prev := b.state
+	b.state = state
+	if state == StateHalfOpen {
+		b.trials = 0
+	}
+
+	if state == StateOpen {
+		slog.Warn("Circuit breaker state changed", "breaker", b.name, "from", prev.String(), "to", state.String(), "consecutive_failures", b.failures)
+	} else {
+		slog.Info("Circuit breaker state changed", "breaker", b.name, "from", prev.String(), "to", state.String())
+	}
+}
+
+// BreakerSet keeps one circuit breaker per key, typically per upstream host.
+type BreakerSet struct {
+	cfg BreakerConfig
+
+	mu       sync.Mutex
+	breakers map[string]*Breaker
+}
+
+// NewBreakerSet creates an empty set of circuit breakers sharing cfg.
+func NewBreakerSet(cfg BreakerConfig) *BreakerSet {
+	return &BreakerSet{
+		cfg:      cfg,
+		breakers: make(map[string]*Breaker),
+	}
+}
+
+// Get returns the breaker for key, creating it on first use.
+func (s *BreakerSet) Get(key string) *Breaker {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, ok := s.breakers[key]
+	if !ok {
+		b = NewBreaker(key, s.cfg)
+		s.breakers[key] = b
+	}
+
+	return b
+}
+
+// Statuses returns a snapshot of every breaker ordered by name.
+func (s *BreakerSet) Statuses() []BreakerStatus {
+	s.mu.Lock()
+	breakers := make([]*Breaker, 0, len(s.breakers))
+	for _, b := range s.breakers {
+		breakers = append(breakers, b)
+	}
+	s.mu.Unlock()
+
+	statuses := make([]BreakerStatus, 0, len(breakers))
+	for _, b := range breakers {
+		statuses = append(statuses, b.Status())
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Name < statuses[j].Name })
+
+	return statuses
+}
@@ -1,23 +1,134 @@
 package router
 
 import (
+	"context"
+	"music-library/internal/auth"
 	"music-library/internal/handlers"
+	"music-library/internal/metrics"
+	"net/http"
+	"time"
 
 	"github.com/gorilla/mux"
 	httpSwagger "github.com/swaggo/http-swagger"
 )
 
-func NewRouter(handler *handlers.SongHandler) *mux.Router {
+// Handlers groups the HTTP handlers served by the router.
+type Handlers struct {
+	Songs     *handlers.SongHandler
+	Jobs      *handlers.JobHandler
+	Imports   *handlers.ImportHandler
+	Health    *handlers.HealthHandler
+	Artists   *handlers.ArtistHandler
+	Albums    *handlers.AlbumHandler
+	Playlists *handlers.PlaylistHandler
+	Auth      *handlers.AuthHandler
+	// RateLimits may be nil to disable rate limiting.
+	RateLimits *handlers.RateLimiter
+}
+
+// Timeouts are the request deadlines per kind of endpoint. A zero value disables the deadline.
+type Timeouts struct {
+	// Read applies to lookups and listings.
+	Read time.Duration
+	// Write applies to changes, including the enrichment call of a synchronous add.
+	Write time.Duration
+	// Import applies to bulk imports.
+	Import time.Duration
+	// Export applies to streaming exports.
+	Export time.Duration
+	// Autocomplete applies to typeahead lookups, which are abandoned as soon as more is typed.
+	Autocomplete time.Duration
+}
+
+// NewRouter registers the API routes. Reads require the reader role, changes the editor role and
+// key administration the admin role; health, metrics and documentation endpoints are open.
+// API routes are rate limited per client before the role is checked, so anonymous floods are limited too.
+func NewRouter(h Handlers, t Timeouts) *mux.Router {
 	r := mux.NewRouter()
+	handler := h.Songs
+
+	limits := h.RateLimits
+	read := func(next http.HandlerFunc) http.HandlerFunc {
+		return limits.Read(handlers.Require(auth.RoleReader, withTimeout(t.Read, next)))
+	}
+	write := func(next http.HandlerFunc) http.HandlerFunc {
+		return limits.Write(handlers.Require(auth.RoleEditor, withTimeout(t.Write, next)))
+	}
+	admin := func(next http.HandlerFunc) http.HandlerFunc {
+		return limits.Write(handlers.Require(auth.RoleAdmin, withTimeout(t.Write, next)))
+	}
 
-	r.HandleFunc("/songs", handler.GetAllSongsHandler).Methods("GET")
-	r.HandleFunc("/song/{id}", handler.GetSongHandler).Methods("GET")
-	r.HandleFunc("/song", handler.AddSongHandler).Methods("POST")
-	r.HandleFunc("/song/{id}", handler.UpdateSongHandler).Methods("PUT")
-	r.HandleFunc("/song/{id}", handler.DeleteSongHandler).Methods("DELETE")
-	r.HandleFunc("/songs", handler.GetSongPaginated)
-	r.HandleFunc("/song/lyrics", handler.GetSongTextPaginatedHandler)
+	r.HandleFunc("/songs/import", limits.Write(handlers.Require(auth.RoleEditor, withTimeout(t.Import, h.Imports.ImportSongsHandler)))).Methods("POST")
+	r.HandleFunc("/songs/export", limits.Read(handlers.Require(auth.RoleReader, withTimeout(t.Export, handler.ExportSongsHandler)))).Methods("GET")
+	r.HandleFunc("/songs/all", read(handler.GetAllSongsHandler)).Methods("GET")
+	r.HandleFunc("/songs/fuzzy", read(handler.FuzzySearchHandler)).Methods("GET")
+	r.HandleFunc("/songs/autocomplete", limits.Read(handlers.Require(auth.RoleReader, withTimeout(t.Autocomplete, handler.AutocompleteHandler)))).Methods("GET")
+	r.HandleFunc("/songs", read(handler.GetSongPaginated)).Methods("GET")
+	// Registered before /song/{id}, which would match it otherwise.
+	r.HandleFunc("/song/lyrics", read(handler.GetSongTextPaginatedHandler)).Methods("GET")
+	r.HandleFunc("/song/{id}", read(handler.GetSongHandler)).Methods("GET")
+	r.HandleFunc("/song/{id}/lyrics", read(handler.GetSongTextPaginatedHandler)).Methods("GET")
+	r.HandleFunc("/song/{id}/sections", read(handler.GetSongSectionsHandler)).Methods("GET")
+	r.HandleFunc("/song/{id}/sections/{number}", read(handler.GetSongSectionHandler)).Methods("GET")
+	r.HandleFunc("/song", write(handler.AddSongHandler)).Methods("POST")
+	r.HandleFunc("/song/{id}", write(handler.UpdateSongHandler)).Methods("PUT")
+	r.HandleFunc("/song/{id}", write(handler.PatchSongHandler)).Methods("PATCH")
+	r.HandleFunc("/song/{id}", write(handler.DeleteSongHandler)).Methods("DELETE")
+	r.HandleFunc("/song/{id}/history", read(handler.GetSongHistoryHandler)).Methods("GET")
+	r.HandleFunc("/song/{id}/revert/{rev}", write(handler.RevertSongHandler)).Methods("POST")
+	r.HandleFunc("/song/{id}/restore", write(handler.RestoreSongHandler)).Methods("POST")
+	r.HandleFunc("/trash", limits.Read(handlers.Require(auth.RoleEditor, withTimeout(t.Read, handler.GetTrashHandler)))).Methods("GET")
+	r.HandleFunc("/song/{id}/artists", read(h.Artists.GetSongCreditsHandler)).Methods("GET")
+	r.HandleFunc("/song/{id}/artists", write(h.Artists.AddSongCreditHandler)).Methods("POST")
+	r.HandleFunc("/song/{id}/artists/{artistID}", write(h.Artists.RemoveSongCreditHandler)).Methods("DELETE")
+	r.HandleFunc("/artists", read(h.Artists.ListArtistsHandler)).Methods("GET")
+	r.HandleFunc("/artists", write(h.Artists.CreateArtistHandler)).Methods("POST")
+	r.HandleFunc("/artists/{id}", read(h.Artists.GetArtistHandler)).Methods("GET")
+	r.HandleFunc("/artists/{id}", write(h.Artists.UpdateArtistHandler)).Methods("PUT")
+	r.HandleFunc("/artists/{id}", write(h.Artists.DeleteArtistHandler)).Methods("DELETE")
+	r.HandleFunc("/artists/{id}/songs", read(h.Artists.GetArtistSongsHandler)).Methods("GET")
+	r.HandleFunc("/albums", read(h.Albums.ListAlbumsHandler)).Methods("GET")
+	r.HandleFunc("/albums", write(h.Albums.CreateAlbumHandler)).Methods("POST")
+	r.HandleFunc("/albums/{id}", read(h.Albums.GetAlbumHandler)).Methods("GET")
+	r.HandleFunc("/albums/{id}", write(h.Albums.UpdateAlbumHandler)).Methods("PUT")
+	r.HandleFunc("/albums/{id}", write(h.Albums.DeleteAlbumHandler)).Methods("DELETE")
+	r.HandleFunc("/albums/{id}/tracks", read(h.Albums.GetAlbumTracksHandler)).Methods("GET")
+	r.HandleFunc("/albums/{id}/tracks/{songID}", write(h.Albums.AttachSongHandler)).Methods("PUT")
+	r.HandleFunc("/albums/{id}/tracks/{songID}", write(h.Albums.DetachSongHandler)).Methods("DELETE")
+	r.HandleFunc("/playlists", read(h.Playlists.ListPlaylistsHandler)).Methods("GET")
+	r.HandleFunc("/playlists", write(h.Playlists.CreatePlaylistHandler)).Methods("POST")
+	r.HandleFunc("/playlists/{id}", read(h.Playlists.GetPlaylistHandler)).Methods("GET")
+	r.HandleFunc("/playlists/{id}", write(h.Playlists.RenamePlaylistHandler)).Methods("PUT")
+	r.HandleFunc("/playlists/{id}", write(h.Playlists.DeletePlaylistHandler)).Methods("DELETE")
+	r.HandleFunc("/playlists/{id}/songs", write(h.Playlists.AddPlaylistSongHandler)).Methods("POST")
+	r.HandleFunc("/playlists/{id}/songs/{songID}", write(h.Playlists.RemovePlaylistSongHandler)).Methods("DELETE")
+	r.HandleFunc("/playlists/{id}/songs/{songID}/position", write(h.Playlists.MovePlaylistSongHandler)).Methods("PUT")
+	r.HandleFunc("/playlists/{id}/order", write(h.Playlists.ReorderPlaylistHandler)).Methods("PUT")
+	r.HandleFunc("/jobs/{id}", read(h.Jobs.GetJobHandler)).Methods("GET")
+	r.HandleFunc("/admin/keys", admin(h.Auth.ListKeysHandler)).Methods("GET")
+	r.HandleFunc("/admin/keys", admin(h.Auth.IssueKeyHandler)).Methods("POST")
+	r.HandleFunc("/admin/keys/{id}", admin(h.Auth.RevokeKeyHandler)).Methods("DELETE")
+	r.Handle("/metrics", metrics.Handler()).Methods("GET")
+	r.HandleFunc("/healthz", h.Health.LivenessHandler).Methods("GET")
+	r.HandleFunc("/readyz", h.Health.ReadinessHandler).Methods("GET")
+	r.HandleFunc("/health/upstream", h.Health.UpstreamHealthHandler).Methods("GET")
 	r.PathPrefix("/swagger/").Handler(httpSwagger.WrapHandler)
 
+	r.Use(metrics.Middleware, h.Auth.Middleware)
+
 	return r
 }
+
+// withTimeout gives the request context of next a deadline of d. The deadline reaches
+// every database query and provider call made on behalf of the request.
+func withTimeout(d time.Duration, next http.HandlerFunc) http.HandlerFunc {
+	if d <= 0 {
+		return next
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), d)
+		defer cancel()
+		next(w, r.WithContext(ctx))
+	}
+}
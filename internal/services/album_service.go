@@ -0,0 +1,133 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"music-library/internal/apperrors"
+	"music-library/internal/models"
+)
+
+type AlbumRepository interface {
+	CreateAlbum(ctx context.Context, album *models.Album) error
+	GetAlbum(ctx context.Context, id int64) (*models.Album, error)
+	ListAlbums(ctx context.Context, artistID int64, page, pageSize int) ([]*models.Album, error)
+	UpdateAlbum(ctx context.Context, id int64, album *models.Album) error
+	DeleteAlbum(ctx context.Context, id int64) error
+	GetAlbumTracks(ctx context.Context, id int64) ([]*models.Song, error)
+	AttachSong(ctx context.Context, albumID int64, songID string, disc, track int) error
+	DetachSong(ctx context.Context, albumID int64, songID string) error
+}
+
+// AlbumService manages albums and their track listings.
+type AlbumService struct {
+	repository AlbumRepository
+	songs      SongGetter
+}
+
+func NewAlbumService(repository AlbumRepository, songs SongGetter) *AlbumService {
+	return &AlbumService{
+		repository: repository,
+		songs:      songs,
+	}
+}
+
+func (s *AlbumService) CreateAlbum(ctx context.Context, title, artist, releaseDate, coverLink string) (*models.Album, error) {
+	album, err := models.NewAlbum(title, artist, releaseDate, coverLink)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repository.CreateAlbum(ctx, album); err != nil {
+		slog.Error("Failed to create album", "title", title, "error", err)
+		return nil, err
+	}
+
+	return s.GetAlbum(ctx, album.ID)
+}
+
+func (s *AlbumService) GetAlbum(ctx context.Context, id int64) (*models.Album, error) {
+	album, err := s.repository.GetAlbum(ctx, id)
+	if err != nil {
+		slog.Error("Failed to get album", "id", id, "error", err)
+		return nil, err
+	}
+
+	return album, nil
+}
+
+func (s *AlbumService) ListAlbums(ctx context.Context, artistID int64, page, pageSize int) ([]*models.Album, error) {
+	albums, err := s.repository.ListAlbums(ctx, artistID, page, pageSize)
+	if err != nil {
+		slog.Error("Failed to list albums", "error", err)
+		return nil, err
+	}
+
+	return albums, nil
+}
+
+func (s *AlbumService) UpdateAlbum(ctx context.Context, id int64, title, artist, releaseDate, coverLink string) (*models.Album, error) {
+	album, err := models.NewAlbum(title, artist, releaseDate, coverLink)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repository.UpdateAlbum(ctx, id, album); err != nil {
+		slog.Error("Failed to update album", "id", id, "error", err)
+		return nil, err
+	}
+
+	return s.GetAlbum(ctx, id)
+}
+
+func (s *AlbumService) DeleteAlbum(ctx context.Context, id int64) error {
+	if err := s.repository.DeleteAlbum(ctx, id); err != nil {
+		slog.Error("Failed to delete album", "id", id, "error", err)
+		return err
+	}
+
+	return nil
+}
+
+func (s *AlbumService) GetAlbumTracks(ctx context.Context, id int64) ([]*models.Song, error) {
+	if _, err := s.GetAlbum(ctx, id); err != nil {
+		return nil, err
+	}
+
+	tracks, err := s.repository.GetAlbumTracks(ctx, id)
+	if err != nil {
+		slog.Error("Failed to get album tracks", "id", id, "error", err)
+		return nil, err
+	}
+
+	return tracks, nil
+}
+
+// AttachSong puts a song on the album and returns it. disc defaults to 1.
+func (s *AlbumService) AttachSong(ctx context.Context, albumID int64, songID string, disc, track int) (*models.Song, error) {
+	if disc == 0 {
+		disc = 1
+	}
+	if disc < 1 || track < 1 {
+		return nil, apperrors.New(apperrors.ErrValidation, "disc and track numbers must be positive")
+	}
+
+	if _, err := s.GetAlbum(ctx, albumID); err != nil {
+		return nil, err
+	}
+
+	if err := s.repository.AttachSong(ctx, albumID, songID, disc, track); err != nil {
+		slog.Error("Failed to attach song", "album_id", albumID, "song_id", songID, "error", err)
+		return nil, err
+	}
+
+	return s.songs.GetSong(ctx, songID)
+}
+
+func (s *AlbumService) DetachSong(ctx context.Context, albumID int64, songID string) error {
+	if err := s.repository.DetachSong(ctx, albumID, songID); err != nil {
+		slog.Error("Failed to detach song", "album_id", albumID, "song_id", songID, "error", err)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,177 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"music-library/internal/apperrors"
+	"music-library/internal/models"
+)
+
+type ArtistRepository interface {
+	CreateArtist(ctx context.Context, artist *models.Artist) error
+	GetArtist(ctx context.Context, id int64) (*models.Artist, error)
+	ListArtists(ctx context.Context, name string, page, pageSize int) ([]*models.Artist, error)
+	RenameArtist(ctx context.Context, id int64, name string) error
+	DeleteArtist(ctx context.Context, id int64) error
+	GetArtistSongs(ctx context.Context, id int64) ([]*models.Song, error)
+	GetSongCredits(ctx context.Context, songID string) ([]models.SongCredit, error)
+	AddSongCredit(ctx context.Context, credit models.SongCredit) error
+	RemoveSongCredit(ctx context.Context, songID string, artistID int64, role models.ArtistRole) error
+}
+
+// SongGetter looks up a song; it is implemented by SongService.
+type SongGetter interface {
+	GetSong(ctx context.Context, id string) (*models.Song, error)
+}
+
+// ArtistService manages artists and their credits on songs. The primary artist of a song
+// follows its group name and is maintained by the song repository.
+type ArtistService struct {
+	repository ArtistRepository
+	songs      SongGetter
+}
+
+func NewArtistService(repository ArtistRepository, songs SongGetter) *ArtistService {
+	return &ArtistService{
+		repository: repository,
+		songs:      songs,
+	}
+}
+
+func (s *ArtistService) CreateArtist(ctx context.Context, name string) (*models.Artist, error) {
+	artist, err := models.NewArtist(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repository.CreateArtist(ctx, artist); err != nil {
+		slog.Error("Failed to create artist", "name", name, "error", err)
+		return nil, err
+	}
+
+	return artist, nil
+}
+
+func (s *ArtistService) GetArtist(ctx context.Context, id int64) (*models.Artist, error) {
+	artist, err := s.repository.GetArtist(ctx, id)
+	if err != nil {
+		slog.Error("Failed to get artist", "id", id, "error", err)
+		return nil, err
+	}
+
+	return artist, nil
+}
+
+func (s *ArtistService) ListArtists(ctx context.Context, name string, page, pageSize int) ([]*models.Artist, error) {
+	artists, err := s.repository.ListArtists(ctx, name, page, pageSize)
+	if err != nil {
+		slog.Error("Failed to list artists", "error", err)
+		return nil, err
+	}
+
+	return artists, nil
+}
+
+// RenameArtist renames an artist and returns it. Songs it is the primary artist of take the new name as group.
+func (s *ArtistService) RenameArtist(ctx context.Context, id int64, name string) (*models.Artist, error) {
+	artist, err := models.NewArtist(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repository.RenameArtist(ctx, id, artist.Name); err != nil {
+		slog.Error("Failed to rename artist", "id", id, "error", err)
+		return nil, err
+	}
+
+	return s.GetArtist(ctx, id)
+}
+
+func (s *ArtistService) DeleteArtist(ctx context.Context, id int64) error {
+	if err := s.repository.DeleteArtist(ctx, id); err != nil {
+		slog.Error("Failed to delete artist", "id", id, "error", err)
+		return err
+	}
+
+	return nil
+}
+
+func (s *ArtistService) GetArtistSongs(ctx context.Context, id int64) ([]*models.Song, error) {
+	if _, err := s.GetArtist(ctx, id); err != nil {
+		return nil, err
+	}
+
+	songs, err := s.repository.GetArtistSongs(ctx, id)
+	if err != nil {
+		slog.Error("Failed to get songs of artist", "id", id, "error", err)
+		return nil, err
+	}
+
+	return songs, nil
+}
+
+func (s *ArtistService) GetSongCredits(ctx context.Context, songID string) ([]models.SongCredit, error) {
+	if _, err := s.songs.GetSong(ctx, songID); err != nil {
+		return nil, err
+	}
+
+	credits, err := s.repository.GetSongCredits(ctx, songID)
+	if err != nil {
+		slog.Error("Failed to get song credits", "song_id", songID, "error", err)
+		return nil, err
+	}
+
+	return credits, nil
+}
+
+// AddSongCredit credits an artist on a song in a secondary role.
+func (s *ArtistService) AddSongCredit(ctx context.Context, credit models.SongCredit) (*models.SongCredit, error) {
+	if err := validateSecondaryRole(credit.Role); err != nil {
+		return nil, err
+	}
+	if credit.Position < 0 {
+		return nil, apperrors.New(apperrors.ErrValidation, "position cannot be negative")
+	}
+
+	if _, err := s.songs.GetSong(ctx, credit.SongID); err != nil {
+		return nil, err
+	}
+	artist, err := s.GetArtist(ctx, credit.ArtistID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repository.AddSongCredit(ctx, credit); err != nil {
+		slog.Error("Failed to add song credit", "song_id", credit.SongID, "artist_id", credit.ArtistID, "error", err)
+		return nil, err
+	}
+
+	credit.ArtistName = artist.Name
+	return &credit, nil
+}
+
+// RemoveSongCredit removes a secondary credit. An empty role removes all of the artist's secondary credits on the song.
+func (s *ArtistService) RemoveSongCredit(ctx context.Context, songID string, artistID int64, role models.ArtistRole) error {
+	if role != "" {
+		if err := validateSecondaryRole(role); err != nil {
+			return err
+		}
+	}
+
+	if err := s.repository.RemoveSongCredit(ctx, songID, artistID, role); err != nil {
+		slog.Error("Failed to remove song credit", "song_id", songID, "artist_id", artistID, "error", err)
+		return err
+	}
+
+	return nil
+}
+
+func validateSecondaryRole(role models.ArtistRole) error {
+	if role == models.RolePrimary {
+		return apperrors.New(apperrors.ErrValidation, "the primary artist is set through the song's group")
+	}
+	if !role.Valid() {
+		return apperrors.New(apperrors.ErrValidation, "unknown artist role %q", role)
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"music-library/internal/apperrors"
+	"music-library/internal/auth"
+	"music-library/internal/models"
+	"strconv"
+)
+
+type APIKeyRepository interface {
+	CreateAPIKey(ctx context.Context, key *models.APIKey, hash string) error
+	EnsureAPIKey(ctx context.Context, key *models.APIKey, hash string) error
+	GetActiveAPIKey(ctx context.Context, hash string) (*models.APIKey, error)
+	ListAPIKeys(ctx context.Context) ([]*models.APIKey, error)
+	RevokeAPIKey(ctx context.Context, id int64) error
+}
+
+// AuthService authenticates API keys and tokens and manages issued keys.
+type AuthService struct {
+	keys   APIKeyRepository
+	tokens *auth.TokenVerifier
+}
+
+// NewAuthService creates the service. tokens may be nil, in which case only API keys are accepted.
+func NewAuthService(keys APIKeyRepository, tokens *auth.TokenVerifier) *AuthService {
+	return &AuthService{
+		keys:   keys,
+		tokens: tokens,
+	}
+}
+
+// Authenticate resolves an API key or token to its principal. Unknown, revoked and
+// invalid credentials yield auth.ErrInvalidCredentials.
+func (s *AuthService) Authenticate(ctx context.Context, credential string) (*auth.Principal, error) {
+	if auth.IsAPIKey(credential) {
+		key, err := s.keys.GetActiveAPIKey(ctx, auth.HashKey(credential))
+		if errors.Is(err, apperrors.ErrNotFound) {
+			return nil, auth.ErrInvalidCredentials
+		} else if err != nil {
+			return nil, err
+		}
+
+		return &auth.Principal{Subject: "key:" + strconv.FormatInt(key.ID, 10), Role: key.Role}, nil
+	}
+
+	if s.tokens == nil {
+		return nil, auth.ErrInvalidCredentials
+	}
+	return s.tokens.Verify(credential)
+}
+
+// IssueKey creates a new API key. The returned key is the only copy of its secret.
+func (s *AuthService) IssueKey(ctx context.Context, name string, role auth.Role) (*models.APIKey, error) {
+	key, err := models.NewAPIKey(name, role)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := auth.GenerateKey()
+	if err != nil {
+		return nil, err
+	}
+	key.Prefix = auth.KeyDisplayPrefix(secret)
+
+	if err := s.keys.CreateAPIKey(ctx, key, auth.HashKey(secret)); err != nil {
+		return nil, err
+	}
+
+	key.Key = secret
+	return key, nil
+}
+
+// EnsureBootstrapKey registers a configured admin key, so that the first keys can be issued.
+// A key that is too short or too repetitive to be safe is refused.
+func (s *AuthService) EnsureBootstrapKey(ctx context.Context, secret string) error {
+	if err := auth.CheckKeyStrength(secret); err != nil {
+		return fmt.Errorf("invalid bootstrap key: %w", err)
+	}
+
+	key := &models.APIKey{Name: "bootstrap", Role: auth.RoleAdmin, Prefix: auth.KeyDisplayPrefix(secret)}
+	if err := s.keys.EnsureAPIKey(ctx, key, auth.HashKey(secret)); err != nil {
+		return err
+	}
+
+	slog.Info("Bootstrap admin key registered", "prefix", key.Prefix)
+	return nil
+}
+
+func (s *AuthService) ListKeys(ctx context.Context) ([]*models.APIKey, error) {
+	return s.keys.ListAPIKeys(ctx)
+}
+
+func (s *AuthService) RevokeKey(ctx context.Context, id int64) error {
+	return s.keys.RevokeAPIKey(ctx, id)
+}
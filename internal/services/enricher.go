@@ -0,0 +1,324 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"music-library/internal/metrics"
+	"music-library/internal/resilience"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// maxProviderBodySize limits how much of a provider response is read.
+const maxProviderBodySize = 1 << 20
+
+// providerDateLayouts lists the release date formats accepted from the provider.
+var providerDateLayouts = []string{"02.01.2006", "2006-01-02"}
+
+// SongDetail is the song information returned by the enrichment provider.
+type SongDetail struct {
+	ReleaseDate string `json:"releaseDate"`
+	Text        string `json:"text"`
+	Link        string `json:"link"`
+}
+
+// Enricher fetches additional song details from an external provider.
+type Enricher interface {
+	Enrich(ctx context.Context, group, song string) (*SongDetail, error)
+}
+
+// ProviderClientError is returned when the provider answers with a 4xx status.
+type ProviderClientError struct {
+	StatusCode int
+	Body       string
+	RetryAfter time.Duration
+}
+
+func (e *ProviderClientError) Error() string {
+	return fmt.Sprintf("provider rejected request with status %d: %s", e.StatusCode, e.Body)
+}
+
+// ProviderServerError is returned when the provider answers with a 5xx or otherwise unexpected status.
+type ProviderServerError struct {
+	StatusCode int
+	Body       string
+	RetryAfter time.Duration
+}
+
+func (e *ProviderServerError) Error() string {
+	return fmt.Sprintf("provider failed with status %d: %s", e.StatusCode, e.Body)
+}
+
+// MalformedPayloadError is returned when the provider response cannot be used.
+type MalformedPayloadError struct {
+	Reason string
+	Err    error
+}
+
+func (e *MalformedPayloadError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("malformed provider payload: %s: %v", e.Reason, e.Err)
+	}
+	return fmt.Sprintf("malformed provider payload: %s", e.Reason)
+}
+
+func (e *MalformedPayloadError) Unwrap() error {
+	return e.Err
+}
+
+// HTTPEnricherConfig holds the settings of the HTTP enrichment provider.
+type HTTPEnricherConfig struct {
+	BaseURL        string
+	Timeout        time.Duration
+	ConnectTimeout time.Duration
+	Retry          resilience.RetryPolicy
+	Breaker        resilience.BreakerConfig
+}
+
+// HTTPEnricher is an Enricher backed by the external song-info HTTP API.
+// Requests are retried with backoff and guarded by a per-host circuit breaker.
+type HTTPEnricher struct {
+	baseURL  *url.URL
+	client   *http.Client
+	retry    resilience.RetryPolicy
+	breakers *resilience.BreakerSet
+}
+
+// NewHTTPEnricher creates a new HTTP enrichment provider.
+func NewHTTPEnricher(cfg HTTPEnricherConfig) (*HTTPEnricher, error) {
+	baseURL, err := url.Parse(cfg.BaseURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid provider URL %q: %w", cfg.BaseURL, err)
+	}
+	if baseURL.Scheme != "http" && baseURL.Scheme != "https" {
+		return nil, fmt.Errorf("invalid provider URL %q: scheme must be http or https", cfg.BaseURL)
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = (&net.Dialer{Timeout: cfg.ConnectTimeout}).DialContext
+
+	if cfg.Retry.MaxAttempts < 1 {
+		cfg.Retry.MaxAttempts = 1
+	}
+
+	return &HTTPEnricher{
+		baseURL: baseURL,
+		client: &http.Client{
+			Timeout:   cfg.Timeout,
+			Transport: transport,
+		},
+		retry:    cfg.Retry,
+		breakers: resilience.NewBreakerSet(cfg.Breaker),
+	}, nil
+}
+
+// Probe checks that the provider is reachable. Any answer below 500 counts as healthy,
+// since the probe sends no song to look up. An open circuit breaker fails the probe without a request.
+func (e *HTTPEnricher) Probe(ctx context.Context) error {
+	status := e.breakers.Get(e.baseURL.Host).Status()
+	if status.State == resilience.StateOpen.String() {
+		return fmt.Errorf("%w for %s", resilience.ErrBreakerOpen, e.baseURL.Host)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, e.baseURL.String(), nil)
+	if err != nil {
+		return fmt.Errorf("failed to build provider request: %w", err)
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("provider is unreachable: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, maxProviderBodySize))
+
+	if resp.StatusCode >= 500 {
+		return fmt.Errorf("provider answered with status %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// BreakerStatuses reports the circuit breaker state of every provider host.
+func (e *HTTPEnricher) BreakerStatuses() []resilience.BreakerStatus {
+	return e.breakers.Statuses()
+}
+
+// Enrich requests the details of a song from the provider.
+func (e *HTTPEnricher) Enrich(ctx context.Context, group, song string) (*SongDetail, error) {
+	reqURL := *e.baseURL
+	query := reqURL.Query()
+	query.Set("group", group)
+	query.Set("song", song)
+	reqURL.RawQuery = query.Encode()
+
+	breaker := e.breakers.Get(reqURL.Host)
+
+	for attempt := 1; ; attempt++ {
+		if err := breaker.Allow(); err != nil {
+			slog.Warn("Skipping song details request", "host", reqURL.Host, "error", err)
+			return nil, err
+		}
+
+		detail, err := e.fetch(ctx, reqURL.String())
+		if ctx.Err() != nil {
+			// The caller gave up; this says nothing about the provider's health.
+			breaker.Ignore()
+			return nil, fmt.Errorf("song details request abandoned: %w", ctx.Err())
+		}
+		if err == nil || !isProviderFailure(err) {
+			breaker.Success()
+		} else {
+			breaker.Failure()
+		}
+		if err == nil {
+			return detail, nil
+		}
+
+		if !isRetryable(err) || attempt >= e.retry.MaxAttempts {
+			return nil, err
+		}
+
+		delay := e.retry.Backoff(attempt)
+		if retryAfter := retryAfterOf(err); retryAfter > delay {
+			if e.retry.MaxDelay > 0 && retryAfter > e.retry.MaxDelay {
+				slog.Warn("Provider asked to retry later than allowed", "retry_after", retryAfter, "max_delay", e.retry.MaxDelay)
+				return nil, err
+			}
+			delay = retryAfter
+		}
+
+		slog.Warn("Retrying song details request", "attempt", attempt, "delay", delay, "error", err)
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("song details request abandoned: %w", ctx.Err())
+		case <-time.After(delay):
+		}
+	}
+}
+
+func (e *HTTPEnricher) fetch(ctx context.Context, reqURL string) (*SongDetail, error) {
+	slog.Info("Fetching song details from API", "url", reqURL)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build provider request: %w", err)
+	}
+
+	start := time.Now()
+	resp, err := e.client.Do(req)
+	if err != nil {
+		metrics.ObserveUpstream(req.URL.Host, 0, start)
+		slog.Error("Failed to fetch song details from API", "error", err)
+		return nil, fmt.Errorf("failed to fetch song details: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxProviderBodySize))
+	metrics.ObserveUpstream(req.URL.Host, resp.StatusCode, start)
+	if err != nil {
+		slog.Error("Failed to read API response", "error", err)
+		return nil, fmt.Errorf("failed to read provider response: %w", err)
+	}
+
+	retryAfter, _ := resilience.ParseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+
+	switch {
+	case resp.StatusCode >= 400 && resp.StatusCode < 500:
+		slog.Error("API rejected the request", "status", resp.StatusCode)
+		return nil, &ProviderClientError{StatusCode: resp.StatusCode, Body: truncate(string(body), 200), RetryAfter: retryAfter}
+	case resp.StatusCode != http.StatusOK:
+		slog.Error("API returned non-OK status", "status", resp.StatusCode)
+		return nil, &ProviderServerError{StatusCode: resp.StatusCode, Body: truncate(string(body), 200), RetryAfter: retryAfter}
+	}
+
+	return parseSongDetail(body)
+}
+
+// isProviderFailure reports whether err means the provider is unhealthy and should count against its breaker.
+func isProviderFailure(err error) bool {
+	var clientErr *ProviderClientError
+	if errors.As(err, &clientErr) {
+		return clientErr.StatusCode == http.StatusTooManyRequests
+	}
+
+	var malformedErr *MalformedPayloadError
+	return !errors.As(err, &malformedErr)
+}
+
+// isRetryable reports whether a failed request may succeed when repeated.
+func isRetryable(err error) bool {
+	var clientErr *ProviderClientError
+	if errors.As(err, &clientErr) {
+		return clientErr.StatusCode == http.StatusTooManyRequests || clientErr.StatusCode == http.StatusRequestTimeout
+	}
+
+	var serverErr *ProviderServerError
+	if errors.As(err, &serverErr) {
+		return serverErr.StatusCode != http.StatusNotImplemented
+	}
+
+	return isProviderFailure(err)
+}
+
+func retryAfterOf(err error) time.Duration {
+	var clientErr *ProviderClientError
+	if errors.As(err, &clientErr) {
+		return clientErr.RetryAfter
+	}
+
+	var serverErr *ProviderServerError
+	if errors.As(err, &serverErr) {
+		return serverErr.RetryAfter
+	}
+
+	return 0
+}
+
+func parseSongDetail(body []byte) (*SongDetail, error) {
+	var detail SongDetail
+	if err := json.Unmarshal(body, &detail); err != nil {
+		slog.Error("Failed to unmarshal song data", "error", err)
+		return nil, &MalformedPayloadError{Reason: "invalid JSON", Err: err}
+	}
+
+	releaseDate, err := normalizeReleaseDate(detail.ReleaseDate)
+	if err != nil {
+		slog.Error("Invalid release date in song data", "release_date", detail.ReleaseDate, "error", err)
+		return nil, &MalformedPayloadError{Reason: "invalid releaseDate", Err: err}
+	}
+	detail.ReleaseDate = releaseDate
+
+	return &detail, nil
+}
+
+// normalizeReleaseDate converts a provider release date to the ISO 8601 form stored in the database.
+// A missing date stays empty, so the song can inherit the release date of its album.
+func normalizeReleaseDate(value string) (string, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return "", nil
+	}
+
+	for _, layout := range providerDateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.Format("2006-01-02"), nil
+		}
+	}
+
+	return "", fmt.Errorf("unsupported release date format %q", value)
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "..."
+}
@@ -0,0 +1,130 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"music-library/internal/fakeapi"
+	"music-library/internal/resilience"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// newTestEnricher returns an enricher asking a fresh fake provider, which the test can control.
+func newTestEnricher(t *testing.T, cfg HTTPEnricherConfig) (*HTTPEnricher, *fakeapi.Server) {
+	t.Helper()
+
+	provider, server := fakeapi.NewTestServer()
+	t.Cleanup(server.Close)
+
+	cfg.BaseURL = server.URL + "/info"
+	if cfg.Timeout == 0 {
+		cfg.Timeout = 5 * time.Second
+	}
+	enricher, err := NewHTTPEnricher(cfg)
+	if err != nil {
+		t.Fatalf("NewHTTPEnricher(): %v", err)
+	}
+	return enricher, provider
+}
+
+func TestEnrichReturnsSongDetails(t *testing.T) {
+	enricher, _ := newTestEnricher(t, HTTPEnricherConfig{})
+
+	detail, err := enricher.Enrich(context.Background(), "Muse", "Supermassive Black Hole")
+	if err != nil {
+		t.Fatalf("Enrich(): %v", err)
+	}
+	if detail.ReleaseDate != "2006-07-16" {
+		t.Errorf("ReleaseDate = %q, want the provider date in ISO form 2006-07-16", detail.ReleaseDate)
+	}
+	if detail.Link != "https://www.youtube.com/watch?v=Xsp3_a-PMTw" || detail.Text == "" {
+		t.Errorf("Enrich() = %+v, want the provider's link and lyrics", detail)
+	}
+}
+
+func TestEnrichUnknownSongIsClientError(t *testing.T) {
+	enricher, _ := newTestEnricher(t, HTTPEnricherConfig{})
+
+	_, err := enricher.Enrich(context.Background(), "Muse", "Unknown")
+	var clientErr *ProviderClientError
+	if !errors.As(err, &clientErr) || clientErr.StatusCode != http.StatusNotFound {
+		t.Fatalf("Enrich() error = %v, want a ProviderClientError with status 404", err)
+	}
+}
+
+func TestEnrichClientErrorCarriesRetryAfter(t *testing.T) {
+	enricher, provider := newTestEnricher(t, HTTPEnricherConfig{})
+	provider.FailNext(1, http.StatusTooManyRequests, "7")
+
+	_, err := enricher.Enrich(context.Background(), "Muse", "Supermassive Black Hole")
+	var clientErr *ProviderClientError
+	if !errors.As(err, &clientErr) || clientErr.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("Enrich() error = %v, want a ProviderClientError with status 429", err)
+	}
+	if clientErr.RetryAfter != 7*time.Second {
+		t.Errorf("RetryAfter = %v, want 7s", clientErr.RetryAfter)
+	}
+}
+
+func TestEnrichServerErrorIsRetried(t *testing.T) {
+	enricher, provider := newTestEnricher(t, HTTPEnricherConfig{
+		Retry:   resilience.RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond},
+		Breaker: resilience.BreakerConfig{FailureThreshold: 5},
+	})
+	provider.FailNext(1, http.StatusServiceUnavailable, "")
+
+	if _, err := enricher.Enrich(context.Background(), "Muse", "Supermassive Black Hole"); err != nil {
+		t.Fatalf("Enrich() after one failure = %v, want the retry to succeed", err)
+	}
+}
+
+func TestEnrichServerErrorAfterLastAttempt(t *testing.T) {
+	enricher, provider := newTestEnricher(t, HTTPEnricherConfig{
+		Breaker: resilience.BreakerConfig{FailureThreshold: 5},
+	})
+	provider.FailNext(1, http.StatusInternalServerError, "")
+
+	_, err := enricher.Enrich(context.Background(), "Muse", "Supermassive Black Hole")
+	var serverErr *ProviderServerError
+	if !errors.As(err, &serverErr) || serverErr.StatusCode != http.StatusInternalServerError {
+		t.Fatalf("Enrich() error = %v, want a ProviderServerError with status 500", err)
+	}
+}
+
+func TestEnrichMalformedPayload(t *testing.T) {
+	enricher, provider := newTestEnricher(t, HTTPEnricherConfig{})
+	provider.AddSong("Muse", "Uprising", fakeapi.SongInfo{ReleaseDate: "last autumn", Text: "Paranoia is in bloom"})
+
+	_, err := enricher.Enrich(context.Background(), "Muse", "Uprising")
+	var malformedErr *MalformedPayloadError
+	if !errors.As(err, &malformedErr) {
+		t.Fatalf("Enrich() error = %v, want a MalformedPayloadError", err)
+	}
+}
+
+func TestEnrichTimeout(t *testing.T) {
+	enricher, provider := newTestEnricher(t, HTTPEnricherConfig{Timeout: 20 * time.Millisecond})
+	provider.SetDelay(time.Second)
+
+	_, err := enricher.Enrich(context.Background(), "Muse", "Supermassive Black Hole")
+	var netErr net.Error
+	if !errors.As(err, &netErr) || !netErr.Timeout() {
+		t.Fatalf("Enrich() error = %v, want a timeout", err)
+	}
+}
+
+func TestEnrichOpenBreakerSkipsProvider(t *testing.T) {
+	enricher, provider := newTestEnricher(t, HTTPEnricherConfig{
+		Breaker: resilience.BreakerConfig{FailureThreshold: 1, OpenTimeout: time.Minute},
+	})
+	provider.FailNext(1, http.StatusBadGateway, "")
+
+	if _, err := enricher.Enrich(context.Background(), "Muse", "Supermassive Black Hole"); err == nil {
+		t.Fatal("Enrich() with a failing provider succeeded")
+	}
+	if _, err := enricher.Enrich(context.Background(), "Muse", "Supermassive Black Hole"); !errors.Is(err, resilience.ErrBreakerOpen) {
+		t.Errorf("Enrich() after the breaker opened = %v, want %v", err, resilience.ErrBreakerOpen)
+	}
+}
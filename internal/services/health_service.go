@@ -0,0 +1,103 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"music-library/internal/models"
+	"time"
+)
+
+type HealthRepository interface {
+	Ping(ctx context.Context) error
+	MigrationVersion(ctx context.Context) (uint, bool, error)
+}
+
+// Prober checks that an external dependency is reachable.
+type Prober interface {
+	Probe(ctx context.Context) error
+}
+
+// HealthConfig holds the settings of the readiness checks.
+type HealthConfig struct {
+	// SchemaVersion is the migration version the service was started with, the oldest it accepts.
+	SchemaVersion uint
+	// CheckTimeout bounds each individual check.
+	CheckTimeout time.Duration
+}
+
+// HealthService runs the readiness checks of the service's dependencies.
+type HealthService struct {
+	repository HealthRepository
+	enricher   Prober
+	cfg        HealthConfig
+}
+
+// NewHealthService creates a health service. enricher may be nil to skip probing the enrichment API.
+func NewHealthService(repository HealthRepository, enricher Prober, cfg HealthConfig) *HealthService {
+	return &HealthService{
+		repository: repository,
+		enricher:   enricher,
+		cfg:        cfg,
+	}
+}
+
+// Readiness runs every check and reports whether the service can take traffic.
+func (s *HealthService) Readiness(ctx context.Context) *models.Readiness {
+	report := &models.Readiness{
+		Status: models.CheckOK,
+		Checks: map[string]models.CheckResult{},
+	}
+
+	report.Checks["database"] = s.check(ctx, s.repository.Ping)
+	report.Checks["migrations"] = s.check(ctx, s.checkMigrations)
+	if s.enricher != nil {
+		report.Checks["enrichment"] = s.check(ctx, s.enricher.Probe)
+	}
+
+	for name, result := range report.Checks {
+		if result.Status != models.CheckOK {
+			report.Status = models.CheckFail
+			slog.Warn("Readiness check failed", "check", name, "error", result.Error)
+		}
+	}
+
+	return report
+}
+
+func (s *HealthService) checkMigrations(ctx context.Context) error {
+	version, dirty, err := s.repository.MigrationVersion(ctx)
+	if err != nil {
+		return err
+	}
+	if dirty {
+		return fmt.Errorf("schema version %d is dirty", version)
+	}
+	// During a rolling deploy a newer instance migrates the schema further while this one
+	// keeps serving, so only a schema older than the one it started with fails the check.
+	if version < s.cfg.SchemaVersion {
+		return fmt.Errorf("schema version %d is older than expected version %d", version, s.cfg.SchemaVersion)
+	}
+	return nil
+}
+
+func (s *HealthService) check(ctx context.Context, fn func(context.Context) error) models.CheckResult {
+	if s.cfg.CheckTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.cfg.CheckTimeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	err := fn(ctx)
+	result := models.CheckResult{
+		Status:    models.CheckOK,
+		LatencyMS: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if err != nil {
+		result.Status = models.CheckFail
+		result.Error = err.Error()
+	}
+
+	return result
+}
@@ -0,0 +1,43 @@
+package services
+
+import (
+	"context"
+	"music-library/internal/models"
+	"testing"
+)
+
+// fixedSchema is a database at a fixed migration version.
+type fixedSchema struct {
+	version uint
+	dirty   bool
+}
+
+func (f fixedSchema) Ping(context.Context) error { return nil }
+
+func (f fixedSchema) MigrationVersion(context.Context) (uint, bool, error) {
+	return f.version, f.dirty, nil
+}
+
+func TestReadinessAcceptsNewerSchema(t *testing.T) {
+	service := NewHealthService(fixedSchema{version: 16}, nil, HealthConfig{SchemaVersion: 15})
+
+	if report := service.Readiness(context.Background()); report.Status != models.CheckOK {
+		t.Errorf("Readiness() = %+v, want ok while a newer instance migrated further", report)
+	}
+}
+
+func TestReadinessFailsOnOlderSchema(t *testing.T) {
+	service := NewHealthService(fixedSchema{version: 14}, nil, HealthConfig{SchemaVersion: 15})
+
+	if report := service.Readiness(context.Background()); report.Checks["migrations"].Status != models.CheckFail {
+		t.Errorf("Readiness() = %+v, want the migrations check to fail", report)
+	}
+}
+
+func TestReadinessFailsOnDirtySchema(t *testing.T) {
+	service := NewHealthService(fixedSchema{version: 15, dirty: true}, nil, HealthConfig{SchemaVersion: 15})
+
+	if report := service.Readiness(context.Background()); report.Checks["migrations"].Status != models.CheckFail {
+		t.Errorf("Readiness() = %+v, want the migrations check to fail", report)
+	}
+}
@@ -0,0 +1,320 @@
+package services
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"music-library/internal/apperrors"
+	"music-library/internal/models"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Supported bulk import formats.
+const (
+	ImportFormatCSV    = "csv"
+	ImportFormatNDJSON = "ndjson"
+)
+
+// maxNDJSONLineSize limits the length of a single NDJSON line.
+const maxNDJSONLineSize = 64 * 1024
+
+// SongImporter adds songs and checks for existing ones; it is implemented by SongService.
+type SongImporter interface {
+	AddSong(ctx context.Context, group, song string) (*models.Song, error)
+	SongExists(ctx context.Context, group, song string) (bool, error)
+}
+
+// ImportConfig holds the settings of bulk imports.
+type ImportConfig struct {
+	Concurrency int
+	MaxRows     int
+}
+
+// ImportService imports song lists in bulk.
+type ImportService struct {
+	songs SongImporter
+	cfg   ImportConfig
+}
+
+func NewImportService(songs SongImporter, cfg ImportConfig) *ImportService {
+	if cfg.Concurrency < 1 {
+		cfg.Concurrency = 1
+	}
+
+	return &ImportService{
+		songs: songs,
+		cfg:   cfg,
+	}
+}
+
+// importRow is a parsed input row waiting to be processed.
+type importRow struct {
+	line  int
+	group string
+	song  string
+	err   error
+	// repeated is set when the same pair appeared earlier in the input.
+	repeated bool
+}
+
+// Import reads group/song pairs from r in the given format and adds each of them
+// through the regular enrichment path. In a dry run rows are only validated.
+// Unless nil, charge is called before each song is added and the row fails when it
+// returns an error; it lets callers meter the enrichment calls an import makes.
+// Songs are added while the input is read, so an input that cannot be read to the end,
+// or a cancelled ctx, does not fail the import: the report covers the rows read so far
+// and is marked aborted.
+func (s *ImportService) Import(ctx context.Context, r io.Reader, format string, dryRun bool, charge func(context.Context) error) (*models.ImportReport, error) {
+	var parse func(io.Reader, func(importRow) bool) error
+	switch format {
+	case ImportFormatCSV:
+		parse = parseCSVRows
+	case ImportFormatNDJSON:
+		parse = parseNDJSONRows
+	default:
+		return nil, apperrors.New(apperrors.ErrValidation, "unsupported import format %q", format)
+	}
+
+	slog.Info("Starting bulk import", "format", format, "dry_run", dryRun, "concurrency", s.cfg.Concurrency)
+
+	report := &models.ImportReport{DryRun: dryRun}
+	rows := make(chan importRow)
+	results := make(chan models.ImportRow)
+
+	var wg sync.WaitGroup
+	seen := make(map[string]struct{})
+	for i := 0; i < s.cfg.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for row := range rows {
+				results <- s.importRow(ctx, row, dryRun, charge)
+			}
+		}()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		for result := range results {
+			report.Rows = append(report.Rows, result)
+		}
+		close(done)
+	}()
+
+	parseErr := parse(r, func(row importRow) bool {
+		if ctx.Err() != nil {
+			return false
+		}
+		if s.cfg.MaxRows > 0 && report.Total >= s.cfg.MaxRows {
+			report.Truncated = true
+			return false
+		}
+		report.Total++
+		report.LastLine = row.line
+		if row.err == nil && row.group != "" && row.song != "" {
+			key := row.group + "\x00" + row.song
+			_, row.repeated = seen[key]
+			seen[key] = struct{}{}
+		}
+		rows <- row
+		return true
+	})
+
+	close(rows)
+	wg.Wait()
+	close(results)
+	<-done
+
+	if parseErr == nil && ctx.Err() != nil {
+		parseErr = fmt.Errorf("bulk import interrupted: %w", ctx.Err())
+	}
+	if parseErr != nil {
+		slog.Error("Bulk import aborted", "last_line", report.LastLine, "error", parseErr)
+		report.Aborted = true
+		report.Error = apperrors.PublicMessage(parseErr)
+		if ctx.Err() != nil {
+			report.Error = "the import was interrupted before the end of the input"
+		}
+	}
+
+	sort.Slice(report.Rows, func(i, j int) bool { return report.Rows[i].Row < report.Rows[j].Row })
+	for _, row := range report.Rows {
+		switch row.Status {
+		case models.ImportCreated:
+			report.Created++
+		case models.ImportDuplicate:
+			report.Duplicate++
+		case models.ImportFailed:
+			report.Failed++
+		case models.ImportValid:
+			report.Valid++
+		}
+	}
+
+	slog.Info("Bulk import finished", "aborted", report.Aborted, "total", report.Total, "created", report.Created, "duplicate", report.Duplicate, "failed", report.Failed, "valid", report.Valid)
+	return report, nil
+}
+
+func (s *ImportService) importRow(ctx context.Context, row importRow, dryRun bool, charge func(context.Context) error) models.ImportRow {
+	result := models.ImportRow{Row: row.line, GroupName: row.group, SongName: row.song}
+
+	if row.err == nil && (row.group == "" || row.song == "") {
+		row.err = fmt.Errorf("group and song are required")
+	}
+	if row.err != nil {
+		result.Status = models.ImportFailed
+		result.Error = row.err.Error()
+		return result
+	}
+
+	if row.repeated {
+		result.Status = models.ImportDuplicate
+		result.Error = "repeated earlier in the input"
+		return result
+	}
+
+	exists, err := s.songs.SongExists(ctx, row.group, row.song)
+	if err != nil {
+		result.Status = models.ImportFailed
+		result.Error = apperrors.PublicMessage(err)
+		return result
+	}
+	if exists {
+		result.Status = models.ImportDuplicate
+		return result
+	}
+
+	if dryRun {
+		result.Status = models.ImportValid
+		return result
+	}
+
+	if charge != nil {
+		if err := charge(ctx); err != nil {
+			result.Status = models.ImportFailed
+			result.Error = "rate limit exhausted before the song could be added"
+			return result
+		}
+	}
+
+	song, err := s.songs.AddSong(ctx, row.group, row.song)
+	if errors.Is(err, apperrors.ErrDuplicate) {
+		result.Status = models.ImportDuplicate
+		return result
+	}
+	if err != nil {
+		result.Status = models.ImportFailed
+		result.Error = apperrors.PublicMessage(err)
+		return result
+	}
+
+	result.Status = models.ImportCreated
+	result.SongID = song.ID
+	return result
+}
+
+// parseCSVRows reads "group,song" records. A leading header row naming the
+// group and song columns is optional and may put them in any position.
+func parseCSVRows(r io.Reader, emit func(importRow) bool) error {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	groupCol, songCol := 0, 1
+	first := true
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			if !emit(importRow{line: parseErr.StartLine, err: parseErr}) {
+				return nil
+			}
+			continue
+		} else if err != nil {
+			return apperrors.Wrap(apperrors.ErrValidation, err, "failed to read CSV input")
+		}
+
+		if first {
+			first = false
+			if g, s, ok := csvHeader(record); ok {
+				groupCol, songCol = g, s
+				continue
+			}
+		}
+
+		line, _ := reader.FieldPos(0)
+		row := importRow{line: line}
+		if len(record) <= groupCol || len(record) <= songCol {
+			row.err = fmt.Errorf("expected at least %d columns, got %d", max(groupCol, songCol)+1, len(record))
+		} else {
+			row.group = strings.TrimSpace(record[groupCol])
+			row.song = strings.TrimSpace(record[songCol])
+		}
+
+		if !emit(row) {
+			return nil
+		}
+	}
+}
+
+func csvHeader(record []string) (groupCol, songCol int, ok bool) {
+	groupCol, songCol = -1, -1
+	for i, name := range record {
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "group", "group_name":
+			groupCol = i
+		case "song", "song_name":
+			songCol = i
+		}
+	}
+
+	return groupCol, songCol, groupCol >= 0 && songCol >= 0
+}
+
+// parseNDJSONRows reads one {"group": ..., "song": ...} object per line.
+func parseNDJSONRows(r io.Reader, emit func(importRow) bool) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 4096), maxNDJSONLineSize)
+
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+
+		var record struct {
+			Group string `json:"group"`
+			Song  string `json:"song"`
+		}
+		row := importRow{line: line}
+		if err := json.Unmarshal([]byte(text), &record); err != nil {
+			row.err = fmt.Errorf("invalid JSON: %w", err)
+		} else {
+			row.group = strings.TrimSpace(record.Group)
+			row.song = strings.TrimSpace(record.Song)
+		}
+
+		if !emit(row) {
+			return nil
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return apperrors.Wrap(apperrors.ErrValidation, err, "failed to read NDJSON input at line %d", line+1)
+	}
+
+	return nil
+}
@@ -0,0 +1,32 @@
+package services
+
+import (
+	"context"
+	"music-library/internal/models"
+	"strings"
+	"testing"
+)
+
+// acceptingSongs adds every song asked for.
+type acceptingSongs struct{}
+
+func (acceptingSongs) AddSong(_ context.Context, group, song string) (*models.Song, error) {
+	return &models.Song{ID: "song-" + song, GroupName: group, SongName: song}, nil
+}
+
+func (acceptingSongs) SongExists(context.Context, string, string) (bool, error) {
+	return false, nil
+}
+
+func TestImportReportsRowsBeforeUnreadableLine(t *testing.T) {
+	input := `{"group": "Muse", "song": "Uprising"}` + "\n" + strings.Repeat("x", maxNDJSONLineSize+1) + "\n"
+	service := NewImportService(acceptingSongs{}, ImportConfig{})
+
+	report, err := service.Import(context.Background(), strings.NewReader(input), ImportFormatNDJSON, false, nil)
+	if err != nil {
+		t.Fatalf("Import() error = %v, want the failure in the report", err)
+	}
+	if !report.Aborted || report.Error == "" || report.LastLine != 1 || report.Created != 1 {
+		t.Errorf("Import() = %+v, want an aborted report with the song of line 1 created", report)
+	}
+}
@@ -0,0 +1,214 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"music-library/internal/apperrors"
+	"music-library/internal/auth"
+	"music-library/internal/models"
+	"music-library/internal/resilience"
+	"net/http"
+	"sync"
+	"time"
+)
+
+type JobRepository interface {
+	CreateJob(ctx context.Context, job models.Job) error
+	GetJob(ctx context.Context, id string) (*models.Job, error)
+	ClaimJob(ctx context.Context, lease time.Duration) (*models.Job, error)
+	// CompleteJob, RetryJob and FailJob apply to the claim made at attempt and return
+	// apperrors.ErrConflict once the job has been claimed again.
+	CompleteJob(ctx context.Context, id string, attempt int, songID string) error
+	RetryJob(ctx context.Context, id string, attempt int, delay time.Duration, lastError string) error
+	FailJob(ctx context.Context, id string, attempt int, lastError string) error
+}
+
+// SongAdder enriches and stores a song; it is implemented by SongService.
+type SongAdder interface {
+	AddSong(ctx context.Context, group, song string) (*models.Song, error)
+	GetSongByName(ctx context.Context, group, song string) (*models.Song, error)
+}
+
+// JobConfig holds the settings of the background ingestion workers.
+type JobConfig struct {
+	Workers      int
+	PollInterval time.Duration
+	// Lease is how long a claimed job is reserved before another worker may take it over.
+	Lease       time.Duration
+	MaxAttempts int
+	Retry       resilience.RetryPolicy
+}
+
+// JobService queues songs for asynchronous ingestion and runs the workers that process them.
+type JobService struct {
+	repository JobRepository
+	songs      SongAdder
+	cfg        JobConfig
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewJobService(repository JobRepository, songs SongAdder, cfg JobConfig) *JobService {
+	if cfg.Workers < 1 {
+		cfg.Workers = 1
+	}
+	if cfg.MaxAttempts < 1 {
+		cfg.MaxAttempts = 1
+	}
+
+	return &JobService{
+		repository: repository,
+		songs:      songs,
+		cfg:        cfg,
+	}
+}
+
+func (s *JobService) EnqueueSong(ctx context.Context, group, song string) (*models.Job, error) {
+	job, err := models.NewJob(group, song)
+	if err != nil {
+		slog.Error("Error creating job model", "error", err)
+		return nil, err
+	}
+	if principal, ok := auth.FromContext(ctx); ok {
+		job.Actor, job.ActorRole = principal.Subject, principal.Role
+	}
+
+	if err := s.repository.CreateJob(ctx, *job); err != nil {
+		slog.Error("Failed to enqueue song", "group", group, "song", song, "error", err)
+		return nil, err
+	}
+
+	slog.Info("Song queued for ingestion", "job_id", job.ID, "group", group, "song", song)
+	return job, nil
+}
+
+func (s *JobService) GetJob(ctx context.Context, id string) (*models.Job, error) {
+	job, err := s.repository.GetJob(ctx, id)
+	if err != nil {
+		slog.Error("Failed to get job from repository", "id", id, "error", err)
+		return nil, err
+	}
+
+	return job, nil
+}
+
+// Start launches the workers. They run until Stop is called or ctx is cancelled.
+func (s *JobService) Start(ctx context.Context) {
+	ctx, s.cancel = context.WithCancel(ctx)
+
+	slog.Info("Starting ingestion workers", "workers", s.cfg.Workers)
+	for i := 0; i < s.cfg.Workers; i++ {
+		s.wg.Add(1)
+		go func(worker int) {
+			defer s.wg.Done()
+			s.run(ctx, worker)
+		}(i + 1)
+	}
+}
+
+// Stop signals the workers to exit and waits for in-flight jobs to finish or ctx to expire.
+// A job still running when ctx expires is picked up again after its lease runs out.
+func (s *JobService) Stop(ctx context.Context) error {
+	if s.cancel != nil {
+		s.cancel()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		slog.Info("Ingestion workers stopped")
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *JobService) run(ctx context.Context, worker int) {
+	for {
+		if ctx.Err() != nil {
+			return
+		}
+
+		job, err := s.repository.ClaimJob(ctx, s.cfg.Lease)
+		if err != nil {
+			slog.Error("Worker failed to claim job", "worker", worker, "error", err)
+		}
+		if job != nil {
+			// A job that has started is allowed to finish within its lease even when the workers are stopping.
+			jobCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), s.cfg.Lease)
+			s.process(jobCtx, worker, job)
+			cancel()
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(s.cfg.PollInterval):
+		}
+	}
+}
+
+func (s *JobService) process(ctx context.Context, worker int, job *models.Job) {
+	slog.Info("Processing job", "worker", worker, "job_id", job.ID, "attempt", job.Attempts)
+
+	// The song is recorded as added by whoever queued it.
+	if job.Actor != "" {
+		ctx = auth.WithPrincipal(ctx, &auth.Principal{Subject: job.Actor, Role: job.ActorRole})
+	}
+
+	song, err := s.songs.AddSong(ctx, job.GroupName, job.SongName)
+	if errors.Is(err, apperrors.ErrDuplicate) {
+		// The song may have been added by an earlier attempt whose lease ran out before it was
+		// recorded; either way the song the job asked for is in the library.
+		song, err = s.songs.GetSongByName(ctx, job.GroupName, job.SongName)
+	}
+	if err == nil {
+		recordJobOutcome(job, s.repository.CompleteJob(ctx, job.ID, job.Attempts, song.ID), "Failed to record job success")
+		return
+	}
+
+	if isPermanentJobError(err) || job.Attempts >= s.cfg.MaxAttempts {
+		slog.Error("Job failed", "job_id", job.ID, "attempts", job.Attempts, "error", err)
+		recordJobOutcome(job, s.repository.FailJob(ctx, job.ID, job.Attempts, apperrors.PublicMessage(err)), "Failed to record job failure")
+		return
+	}
+
+	delay := s.cfg.Retry.Backoff(job.Attempts)
+	slog.Warn("Job attempt failed, rescheduling", "job_id", job.ID, "attempts", job.Attempts, "delay", delay, "error", err)
+	recordJobOutcome(job, s.repository.RetryJob(ctx, job.ID, job.Attempts, delay, apperrors.PublicMessage(err)), "Failed to reschedule job")
+}
+
+// recordJobOutcome logs err from recording the outcome of job. A lost lease is expected when a job
+// outlives it: the worker that claimed the job again records the outcome instead.
+func recordJobOutcome(job *models.Job, err error, msg string) {
+	switch {
+	case err == nil:
+	case errors.Is(err, apperrors.ErrConflict):
+		slog.Warn("Job lease lost before its outcome was recorded", "job_id", job.ID, "attempt", job.Attempts)
+	default:
+		slog.Error(msg, "job_id", job.ID, "error", err)
+	}
+}
+
+// isPermanentJobError reports whether repeating the job cannot change the outcome.
+func isPermanentJobError(err error) bool {
+	if errors.Is(err, apperrors.ErrDuplicate) || errors.Is(err, apperrors.ErrValidation) {
+		return true
+	}
+
+	var clientErr *ProviderClientError
+	if errors.As(err, &clientErr) {
+		return clientErr.StatusCode != http.StatusTooManyRequests && clientErr.StatusCode != http.StatusRequestTimeout
+	}
+
+	var malformedErr *MalformedPayloadError
+	return errors.As(err, &malformedErr)
+}
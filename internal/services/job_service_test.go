@@ -0,0 +1,51 @@
+package services
+
+import (
+	"context"
+	"music-library/internal/apperrors"
+	"music-library/internal/models"
+	"testing"
+	"time"
+)
+
+// recordedJobs records the outcomes reported for jobs. Only the methods the tests reach are
+// implemented; the embedded interface is nil, so any other call fails the test with a panic.
+type recordedJobs struct {
+	JobRepository
+
+	completed map[string]string
+	failed    map[string]string
+}
+
+func (r *recordedJobs) CompleteJob(_ context.Context, id string, _ int, songID string) error {
+	r.completed[id] = songID
+	return nil
+}
+
+func (r *recordedJobs) FailJob(_ context.Context, id string, _ int, lastError string) error {
+	r.failed[id] = lastError
+	return nil
+}
+
+// existingSongs is a library that already holds every song asked for.
+type existingSongs struct{}
+
+func (existingSongs) AddSong(_ context.Context, group, song string) (*models.Song, error) {
+	return nil, apperrors.New(apperrors.ErrDuplicate, "song %q by %q already exists", song, group)
+}
+
+func (existingSongs) GetSongByName(_ context.Context, group, song string) (*models.Song, error) {
+	return &models.Song{ID: "song-1", GroupName: group, SongName: song}, nil
+}
+
+func TestProcessCompletesJobForExistingSong(t *testing.T) {
+	jobs := &recordedJobs{completed: map[string]string{}, failed: map[string]string{}}
+	service := NewJobService(jobs, existingSongs{}, JobConfig{Lease: time.Minute})
+
+	job := &models.Job{ID: "job-1", GroupName: "Muse", SongName: "Uprising", Attempts: 2}
+	service.process(context.Background(), 1, job)
+
+	if jobs.completed["job-1"] != "song-1" || len(jobs.failed) != 0 {
+		t.Errorf("process() completed %v and failed %v, want job-1 completed with song-1", jobs.completed, jobs.failed)
+	}
+}
@@ -0,0 +1,82 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+)
+
+// LyricsBackfiller parses the lyrics of up to limit songs stored without sections.
+type LyricsBackfiller interface {
+	BackfillSongSections(ctx context.Context, limit int) (int, error)
+}
+
+// lyricsBackfillBatch is the number of songs whose lyrics are parsed in one transaction.
+const lyricsBackfillBatch = 500
+
+// LyricsBackfillService parses the lyrics of the songs added before sections were stored. Lyrics
+// reads parse such songs themselves in the meantime, so it runs in the background.
+type LyricsBackfillService struct {
+	repository LyricsBackfiller
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewLyricsBackfillService(repository LyricsBackfiller) *LyricsBackfillService {
+	return &LyricsBackfillService{repository: repository}
+}
+
+// Start launches the backfill. It runs until every song is parsed, Stop is called or ctx is cancelled.
+func (s *LyricsBackfillService) Start(ctx context.Context) {
+	ctx, s.cancel = context.WithCancel(ctx)
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		s.Backfill(ctx)
+	}()
+}
+
+// Stop signals the backfill to exit and waits for the running batch to finish or ctx to expire.
+func (s *LyricsBackfillService) Stop(ctx context.Context) error {
+	if s.cancel == nil {
+		return nil
+	}
+	s.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Backfill parses the lyrics of every song stored without sections, one batch at a time, and
+// returns how many songs were parsed. A failed batch stops it; the next start resumes.
+func (s *LyricsBackfillService) Backfill(ctx context.Context) int {
+	total := 0
+	for ctx.Err() == nil {
+		n, err := s.repository.BackfillSongSections(ctx, lyricsBackfillBatch)
+		if err != nil {
+			slog.Error("Failed to backfill lyrics sections", "parsed", total, "error", err)
+			break
+		}
+		total += n
+		if n < lyricsBackfillBatch {
+			break
+		}
+	}
+
+	if total > 0 {
+		slog.Info("Backfilled lyrics sections", "songs", total)
+	}
+	return total
+}
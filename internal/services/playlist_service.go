@@ -0,0 +1,180 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"music-library/internal/apperrors"
+	"music-library/internal/models"
+	"slices"
+)
+
+type PlaylistRepository interface {
+	CreatePlaylist(ctx context.Context, playlist *models.Playlist) error
+	GetPlaylist(ctx context.Context, id int64) (*models.Playlist, error)
+	ListPlaylists(ctx context.Context, page, pageSize int) ([]*models.Playlist, error)
+	GetPlaylistSongs(ctx context.Context, id int64) ([]*models.Song, error)
+	RenamePlaylist(ctx context.Context, id int64, name string) error
+	DeletePlaylist(ctx context.Context, id int64) error
+	UpdatePlaylistSongs(ctx context.Context, id int64, expectedVersion int, fn func([]string) ([]string, error)) (int, error)
+}
+
+// PlaylistService manages playlists and the order of their songs. Positions are 1-based.
+// Deleting a song removes it from every playlist.
+type PlaylistService struct {
+	repository PlaylistRepository
+	songs      SongGetter
+}
+
+func NewPlaylistService(repository PlaylistRepository, songs SongGetter) *PlaylistService {
+	return &PlaylistService{
+		repository: repository,
+		songs:      songs,
+	}
+}
+
+func (s *PlaylistService) CreatePlaylist(ctx context.Context, name string) (*models.Playlist, error) {
+	playlist, err := models.NewPlaylist(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repository.CreatePlaylist(ctx, playlist); err != nil {
+		slog.Error("Failed to create playlist", "error", err)
+		return nil, err
+	}
+
+	playlist.Songs = []*models.Song{}
+	return playlist, nil
+}
+
+// GetPlaylist returns the playlist with its songs in order.
+func (s *PlaylistService) GetPlaylist(ctx context.Context, id int64) (*models.Playlist, error) {
+	playlist, err := s.repository.GetPlaylist(ctx, id)
+	if err != nil {
+		slog.Error("Failed to get playlist", "id", id, "error", err)
+		return nil, err
+	}
+
+	playlist.Songs, err = s.repository.GetPlaylistSongs(ctx, id)
+	if err != nil {
+		slog.Error("Failed to get playlist songs", "id", id, "error", err)
+		return nil, err
+	}
+
+	return playlist, nil
+}
+
+func (s *PlaylistService) ListPlaylists(ctx context.Context, page, pageSize int) ([]*models.Playlist, error) {
+	playlists, err := s.repository.ListPlaylists(ctx, page, pageSize)
+	if err != nil {
+		slog.Error("Failed to list playlists", "error", err)
+		return nil, err
+	}
+
+	return playlists, nil
+}
+
+func (s *PlaylistService) RenamePlaylist(ctx context.Context, id int64, name string) (*models.Playlist, error) {
+	playlist, err := models.NewPlaylist(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repository.RenamePlaylist(ctx, id, playlist.Name); err != nil {
+		slog.Error("Failed to rename playlist", "id", id, "error", err)
+		return nil, err
+	}
+
+	return s.GetPlaylist(ctx, id)
+}
+
+func (s *PlaylistService) DeletePlaylist(ctx context.Context, id int64) error {
+	if err := s.repository.DeletePlaylist(ctx, id); err != nil {
+		slog.Error("Failed to delete playlist", "id", id, "error", err)
+		return err
+	}
+
+	return nil
+}
+
+// AddSong inserts a song at position, or appends it when position is 0.
+func (s *PlaylistService) AddSong(ctx context.Context, id int64, songID string, position int) (*models.Playlist, error) {
+	if _, err := s.songs.GetSong(ctx, songID); err != nil {
+		return nil, err
+	}
+
+	return s.updateSongs(ctx, id, 0, func(current []string) ([]string, error) {
+		if slices.Index(current, songID) >= 0 {
+			return nil, apperrors.New(apperrors.ErrDuplicate, "song %s is already in playlist %d", songID, id)
+		}
+		if position == 0 {
+			position = len(current) + 1
+		}
+		if position < 1 || position > len(current)+1 {
+			return nil, apperrors.New(apperrors.ErrValidation, "position must be between 1 and %d", len(current)+1)
+		}
+
+		return slices.Insert(slices.Clone(current), position-1, songID), nil
+	})
+}
+
+func (s *PlaylistService) RemoveSong(ctx context.Context, id int64, songID string) (*models.Playlist, error) {
+	return s.updateSongs(ctx, id, 0, func(current []string) ([]string, error) {
+		i := slices.Index(current, songID)
+		if i < 0 {
+			return nil, apperrors.New(apperrors.ErrNotFound, "song %s is not in playlist %d", songID, id)
+		}
+
+		return slices.Delete(slices.Clone(current), i, i+1), nil
+	})
+}
+
+// MoveSong moves a song to position, shifting the songs in between. A positive version must match the playlist's.
+func (s *PlaylistService) MoveSong(ctx context.Context, id int64, songID string, position, version int) (*models.Playlist, error) {
+	return s.updateSongs(ctx, id, version, func(current []string) ([]string, error) {
+		i := slices.Index(current, songID)
+		if i < 0 {
+			return nil, apperrors.New(apperrors.ErrNotFound, "song %s is not in playlist %d", songID, id)
+		}
+		if position < 1 || position > len(current) {
+			return nil, apperrors.New(apperrors.ErrValidation, "position must be between 1 and %d", len(current))
+		}
+
+		next := slices.Delete(slices.Clone(current), i, i+1)
+		return slices.Insert(next, position-1, songID), nil
+	})
+}
+
+// Reorder replaces the order of the playlist. songIDs must list exactly the songs of the
+// playlist, and version must match the playlist's so that changes made since the client
+// read it are not lost.
+func (s *PlaylistService) Reorder(ctx context.Context, id int64, songIDs []string, version int) (*models.Playlist, error) {
+	if version < 1 {
+		return nil, apperrors.New(apperrors.ErrValidation, "version is required to reorder a playlist")
+	}
+
+	return s.updateSongs(ctx, id, version, func(current []string) ([]string, error) {
+		if len(songIDs) != len(current) {
+			return nil, apperrors.New(apperrors.ErrValidation, "order must list all %d songs of the playlist", len(current))
+		}
+
+		seen := make(map[string]bool, len(songIDs))
+		for _, songID := range songIDs {
+			if seen[songID] || slices.Index(current, songID) < 0 {
+				return nil, apperrors.New(apperrors.ErrValidation, "order must list every song of the playlist exactly once")
+			}
+			seen[songID] = true
+		}
+
+		return songIDs, nil
+	})
+}
+
+func (s *PlaylistService) updateSongs(ctx context.Context, id int64, version int, fn func([]string) ([]string, error)) (*models.Playlist, error) {
+	if _, err := s.repository.UpdatePlaylistSongs(ctx, id, version, fn); err != nil {
+		slog.Error("Failed to update playlist songs", "id", id, "error", err)
+		return nil, err
+	}
+
+	return s.GetPlaylist(ctx, id)
+}
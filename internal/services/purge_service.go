@@ -0,0 +1,117 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// SongPurger permanently deletes songs that have been in the trash since before cutoff.
+type SongPurger interface {
+	PurgeDeletedSongs(ctx context.Context, cutoff time.Time, limit int) (int, error)
+}
+
+// PurgeConfig holds the settings of the trash purger.
+type PurgeConfig struct {
+	// Retention is how long deleted songs stay in the trash. Zero disables purging.
+	Retention time.Duration
+	Interval  time.Duration
+}
+
+// purgeBatchSize is the number of songs deleted per transaction.
+const purgeBatchSize = 500
+
+// PurgeService periodically removes songs from the trash once their retention has passed.
+type PurgeService struct {
+	repository SongPurger
+	cfg        PurgeConfig
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewPurgeService(repository SongPurger, cfg PurgeConfig) *PurgeService {
+	if cfg.Interval <= 0 {
+		cfg.Interval = time.Hour
+	}
+
+	return &PurgeService{
+		repository: repository,
+		cfg:        cfg,
+	}
+}
+
+// Start launches the purger. It runs until Stop is called or ctx is cancelled.
+func (s *PurgeService) Start(ctx context.Context) {
+	if s.cfg.Retention <= 0 {
+		slog.Info("Trash purging disabled")
+		return
+	}
+
+	ctx, s.cancel = context.WithCancel(ctx)
+
+	slog.Info("Starting trash purger", "retention", s.cfg.Retention, "interval", s.cfg.Interval)
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		s.run(ctx)
+	}()
+}
+
+// Stop signals the purger to exit and waits for the running batch to finish or ctx to expire.
+func (s *PurgeService) Stop(ctx context.Context) error {
+	if s.cancel == nil {
+		return nil
+	}
+	s.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		slog.Info("Trash purger stopped")
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *PurgeService) run(ctx context.Context) {
+	for {
+		s.Purge(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(s.cfg.Interval):
+		}
+	}
+}
+
+// Purge deletes every song whose retention has passed, one batch at a time, and returns how many were deleted.
+func (s *PurgeService) Purge(ctx context.Context) int {
+	cutoff := time.Now().Add(-s.cfg.Retention)
+
+	total := 0
+	for ctx.Err() == nil {
+		purged, err := s.repository.PurgeDeletedSongs(ctx, cutoff, purgeBatchSize)
+		if err != nil {
+			slog.Error("Failed to purge trash", "error", err)
+			break
+		}
+		total += purged
+		if purged < purgeBatchSize {
+			break
+		}
+	}
+
+	if total > 0 {
+		slog.Info("Purged songs from trash", "count", total, "cutoff", cutoff)
+	}
+	return total
+}
@@ -1,84 +1,156 @@
 package services
 
 import (
-	"encoding/json"
+	"context"
+	"errors"
 	"fmt"
-	"io"
 	"log/slog"
+	"math"
+	"music-library/internal/apperrors"
 	"music-library/internal/models"
 	"net/http"
-	"net/url"
+	"slices"
+	"strings"
+	"time"
 )
 
 type SongRepository interface {
-	DeleteSongRepository(id string) error
-	UpdateSongRepository(id string, song *models.Song) error
-	GetAllSongsRepository() ([]*models.Song, error)
-	GetSongRepository(id string) (*models.Song, error)
-	AddSongRepository(song models.Song) error
-	GetSongPaginated(filter map[string]string, page, pageSize int) ([]*models.Song, error)
-	GetSongTextPaginated(id string, page, pageSize int) ([]string, error)
+	DeleteSongRepository(ctx context.Context, id string, expectedVersion int) error
+	UpdateSongRepository(ctx context.Context, id string, song *models.Song, expectedVersion int) error
+	GetAllSongsRepository(ctx context.Context) ([]*models.Song, error)
+	GetSongRepository(ctx context.Context, id string) (*models.Song, error)
+	AddSongRepository(ctx context.Context, song models.Song) error
+	GetSongByName(ctx context.Context, group, song string) (*models.Song, error)
+	SongExists(ctx context.Context, group, song string) (bool, error)
+	GetSongPaginated(ctx context.Context, filter *models.SongFilter, page, pageSize int) ([]*models.Song, error)
+	GetSongsByCursor(ctx context.Context, filter *models.SongFilter, cursor *models.SongCursor, limit int) ([]*models.Song, error)
+	CountSongs(ctx context.Context, filter *models.SongFilter) (int64, error)
+	EstimateSongs(ctx context.Context, filter *models.SongFilter) (int64, error)
+	GetSongTextPaginated(ctx context.Context, id string, page, pageSize int) ([]models.LyricsSection, int, error)
+	GetSongSections(ctx context.Context, id string, from, to int) ([]models.LyricsSection, int, error)
+	ExportSongs(ctx context.Context, filter *models.SongFilter, fn func(*models.Song) error) error
+	GetSongRevisions(ctx context.Context, id string, page, pageSize int) ([]*models.SongRevision, error)
+	RevertSongRepository(ctx context.Context, id string, revision, expectedVersion int) error
+	GetTrash(ctx context.Context, page, pageSize int) ([]*models.Song, error)
+	RestoreSongRepository(ctx context.Context, id string, expectedVersion int) error
+	FuzzySearchSongs(ctx context.Context, query *models.FuzzyQuery, page, pageSize int) ([]*models.Song, error)
+	SuggestNames(ctx context.Context, field, term string, limit int) ([]string, error)
+	Autocomplete(ctx context.Context, term string, limit int) ([]models.SongSuggestion, error)
+}
+
+// SearchConfig holds the text search configurations songs may use.
+type SearchConfig struct {
+	// Languages are the PostgreSQL text search configurations searched and allowed as song languages.
+	Languages []string
+	// DefaultLanguage is the language of songs added without one.
+	DefaultLanguage string
+	// SimilarityThreshold is the minimum trigram word similarity of a fuzzy match when none is requested.
+	SimilarityThreshold float64
 }
 
 type SongService struct {
 	repository SongRepository
-	APIURL     string
+	enricher   Enricher
+	search     SearchConfig
 }
 
-func NewSongService(repository SongRepository) *SongService {
+func NewSongService(repository SongRepository, enricher Enricher, search SearchConfig) *SongService {
+	if search.DefaultLanguage == "" {
+		search.DefaultLanguage = "simple"
+	}
+	if len(search.Languages) == 0 {
+		search.Languages = []string{search.DefaultLanguage}
+	}
+	if search.SimilarityThreshold <= 0 {
+		search.SimilarityThreshold = 0.3
+	}
+
 	return &SongService{
 		repository: repository,
+		enricher:   enricher,
+		search:     search,
 	}
 }
 
-func (s *SongService) AddSong(group, song string) error {
-	groupEncoded := url.QueryEscape(group)
-	songEncoded := url.QueryEscape(song)
-
-	apiURL := fmt.Sprintf("%s?group=%s&song=%s", s.APIURL, groupEncoded, songEncoded)
-	slog.Info("Fetching song details from API", "url", apiURL)
+// songLanguage returns the language to store for a song, falling back to the default one.
+func (s *SongService) songLanguage(language string) (string, error) {
+	if language == "" {
+		return s.search.DefaultLanguage, nil
+	}
+	if !slices.Contains(s.search.Languages, language) {
+		return "", apperrors.New(apperrors.ErrValidation, "language must be one of %s", strings.Join(s.search.Languages, ", "))
+	}
+	return language, nil
+}
 
-	resp, err := http.Get(apiURL)
-	if err != nil {
-		slog.Error("Failed to fetch song details from API", "error", err)
-		return err
+// searchFilter resolves the languages of a search: the one requested with Lang, or all of them.
+func (s *SongService) searchFilter(filter *models.SongFilter) (*models.SongFilter, error) {
+	if filter.Q == "" {
+		return filter, nil
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		slog.Error("API returned non-OK status", "status", resp.StatusCode)
-		return fmt.Errorf("API returned status %d", resp.StatusCode)
+	languages := s.search.Languages
+	if filter.Lang != "" {
+		if !slices.Contains(s.search.Languages, filter.Lang) {
+			return nil, apperrors.New(apperrors.ErrValidation, "lang must be one of %s", strings.Join(s.search.Languages, ", "))
+		}
+		languages = []string{filter.Lang}
 	}
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		slog.Error("Failed to read API response", "error", err)
-		return err
+	resolved := *filter
+	resolved.Languages = languages
+	return &resolved, nil
+}
+
+// SearchLanguages returns the text search configurations songs can be searched in.
+func (s *SongService) SearchLanguages() []string {
+	return s.search.Languages
+}
+
+// enrichmentError maps a failed enrichment to a domain error. The provider not knowing the song or
+// rejecting its names is the client's problem; anything else is an upstream failure.
+func enrichmentError(err error, group, song string) error {
+	var clientErr *ProviderClientError
+	if errors.As(err, &clientErr) {
+		switch clientErr.StatusCode {
+		case http.StatusNotFound, http.StatusGone:
+			return apperrors.Wrap(apperrors.ErrNotFound, err, "the song-info provider does not know %q by %q", song, group)
+		case http.StatusTooManyRequests, http.StatusRequestTimeout:
+		default:
+			return apperrors.Wrap(apperrors.ErrValidation, err, "the song-info provider rejected %q by %q", song, group)
+		}
 	}
+	return apperrors.Wrap(apperrors.ErrUpstream, err, "failed to fetch details of %q by %q from the song-info provider", song, group)
+}
 
-	var songDetail models.Song
-	if err := json.Unmarshal(body, &songDetail); err != nil {
-		slog.Error("Failed to unmarshal song data", "error", err)
-		return err
+func (s *SongService) AddSong(ctx context.Context, group, song string) (*models.Song, error) {
+	songDetail, err := s.enricher.Enrich(ctx, group, song)
+	if err != nil {
+		slog.Error("Failed to enrich song", "group", group, "song", song, "error", err)
+		if ctx.Err() != nil {
+			return nil, err
+		}
+		return nil, enrichmentError(err, group, song)
 	}
 
 	fullSong, err := models.NewSong(group, song, songDetail.Text, songDetail.Link, songDetail.ReleaseDate)
 	if err != nil {
 		slog.Error("Error creating song model", "error", err)
-		return err
+		return nil, err
 	}
+	fullSong.Language = s.search.DefaultLanguage
 
-	if err := s.repository.AddSongRepository(*fullSong); err != nil {
+	if err := s.repository.AddSongRepository(ctx, *fullSong); err != nil {
 		slog.Error("Failed to add song to repository", "song", fullSong, "error", err)
-		return err
+		return nil, err
 	}
 
 	slog.Info("Successfully added song to repository", "song", fullSong)
-	return nil
+	return fullSong, nil
 }
 
-func (s *SongService) GetSong(id string) (*models.Song, error) {
-	song, err := s.repository.GetSongRepository(id)
+func (s *SongService) GetSong(ctx context.Context, id string) (*models.Song, error) {
+	song, err := s.repository.GetSongRepository(ctx, id)
 	if err != nil {
 		slog.Error("Failed to get song from repository", "id", id, "error", err)
 		return nil, err
@@ -88,10 +160,31 @@ func (s *SongService) GetSong(id string) (*models.Song, error) {
 	return song, nil
 }
 
-func (s *SongService) GetAllSongs() ([]*models.Song, error) {
+// GetSongByName returns the song of the group with the given name.
+func (s *SongService) GetSongByName(ctx context.Context, group, song string) (*models.Song, error) {
+	found, err := s.repository.GetSongByName(ctx, group, song)
+	if err != nil {
+		slog.Error("Failed to get song by name from repository", "group", group, "song", song, "error", err)
+		return nil, err
+	}
+
+	return found, nil
+}
+
+func (s *SongService) SongExists(ctx context.Context, group, song string) (bool, error) {
+	exists, err := s.repository.SongExists(ctx, group, song)
+	if err != nil {
+		slog.Error("Failed to check song existence", "group", group, "song", song, "error", err)
+		return false, err
+	}
+
+	return exists, nil
+}
+
+func (s *SongService) GetAllSongs(ctx context.Context) ([]*models.Song, error) {
 	slog.Info("Fetching all songs from repository")
 
-	songs, err := s.repository.GetAllSongsRepository()
+	songs, err := s.repository.GetAllSongsRepository(ctx)
 	if err != nil {
 		slog.Error("Failed to get all songs from repository", "error", err)
 		return nil, err
@@ -101,7 +194,9 @@ func (s *SongService) GetAllSongs() ([]*models.Song, error) {
 	return songs, nil
 }
 
-func (s *SongService) UpdateSong(id string, updateSong *models.Song) error {
+// UpdateSong replaces the song. Without a language the song keeps its current one. A positive
+// version must match the song's.
+func (s *SongService) UpdateSong(ctx context.Context, id string, updateSong *models.Song, version int) error {
 	slog.Info("Updating song in repository", "id", id, "song", updateSong)
 
 	fullSong, err := models.NewSong(updateSong.GroupName, updateSong.SongName, updateSong.Text, updateSong.Link, updateSong.ReleaseDate)
@@ -109,8 +204,13 @@ func (s *SongService) UpdateSong(id string, updateSong *models.Song) error {
 		slog.Error("Error creating song model", "error", err)
 		return err
 	}
+	if updateSong.Language != "" {
+		if fullSong.Language, err = s.songLanguage(updateSong.Language); err != nil {
+			return err
+		}
+	}
 
-	if err := s.repository.UpdateSongRepository(id, fullSong); err != nil {
+	if err := s.repository.UpdateSongRepository(ctx, id, fullSong, version); err != nil {
 		slog.Error("Failed to update song in repository", "id", id, "error", err)
 		return err
 	}
@@ -119,10 +219,36 @@ func (s *SongService) UpdateSong(id string, updateSong *models.Song) error {
 	return nil
 }
 
-func (s *SongService) DeleteSong(id string) error {
+// PatchSong applies a merge patch to the song and returns the updated song. A positive version must
+// match the song's. The patch is only stored if the song did not change after it was read.
+func (s *SongService) PatchSong(ctx context.Context, id string, patch *models.SongPatch, version int) (*models.Song, error) {
+	song, err := s.repository.GetSongRepository(ctx, id)
+	if err != nil {
+		slog.Error("Failed to get song to patch", "id", id, "error", err)
+		return nil, err
+	}
+	if version > 0 && song.Version != version {
+		return nil, apperrors.New(apperrors.ErrPrecondition, "song %s was modified: version is %d, not %d", id, song.Version, version)
+	}
+
+	patched := patch.Apply(*song)
+	if patched.Language, err = s.songLanguage(patched.Language); err != nil {
+		return nil, err
+	}
+	if err := s.repository.UpdateSongRepository(ctx, id, &patched, song.Version); err != nil {
+		slog.Error("Failed to patch song in repository", "id", id, "error", err)
+		return nil, err
+	}
+
+	slog.Info("Successfully patched song", "id", id)
+	return s.repository.GetSongRepository(ctx, id)
+}
+
+// DeleteSong moves the song to the trash. A positive version must match the song's.
+func (s *SongService) DeleteSong(ctx context.Context, id string, version int) error {
 	slog.Info("Deleting song from repository", "id", id)
 
-	if err := s.repository.DeleteSongRepository(id); err != nil {
+	if err := s.repository.DeleteSongRepository(ctx, id, version); err != nil {
 		slog.Error("Failed to delete song from repository", "id", id, "error", err)
 		return err
 	}
@@ -131,28 +257,292 @@ func (s *SongService) DeleteSong(id string) error {
 	return nil
 }
 
-func (s *SongService) GetSongPaginated(filter map[string]string, page, pageSize int) ([]*models.Song, error) {
+// GetSongHistory returns a page of the song's revisions, newest first.
+func (s *SongService) GetSongHistory(ctx context.Context, id string, page, pageSize int) ([]*models.SongRevision, error) {
+	revisions, err := s.repository.GetSongRevisions(ctx, id, page, pageSize)
+	if err != nil {
+		slog.Error("Failed to fetch song history", "id", id, "error", err)
+		return nil, err
+	}
+
+	return revisions, nil
+}
+
+// RevertSong restores the song to the given revision and returns it. A positive version must match the song's.
+func (s *SongService) RevertSong(ctx context.Context, id string, revision, version int) (*models.Song, error) {
+	if err := s.repository.RevertSongRepository(ctx, id, revision, version); err != nil {
+		slog.Error("Failed to revert song", "id", id, "revision", revision, "error", err)
+		return nil, err
+	}
+
+	slog.Info("Successfully reverted song", "id", id, "revision", revision)
+	return s.repository.GetSongRepository(ctx, id)
+}
+
+// GetTrash returns a page of the deleted songs, most recently deleted first.
+func (s *SongService) GetTrash(ctx context.Context, page, pageSize int) ([]*models.Song, error) {
+	songs, err := s.repository.GetTrash(ctx, page, pageSize)
+	if err != nil {
+		slog.Error("Failed to fetch trash", "error", err)
+		return nil, err
+	}
+
+	return songs, nil
+}
+
+// RestoreSong takes the song out of the trash and returns it. A positive version must match the song's.
+func (s *SongService) RestoreSong(ctx context.Context, id string, version int) (*models.Song, error) {
+	if err := s.repository.RestoreSongRepository(ctx, id, version); err != nil {
+		slog.Error("Failed to restore song", "id", id, "error", err)
+		return nil, err
+	}
+
+	slog.Info("Successfully restored song", "id", id)
+	return s.repository.GetSongRepository(ctx, id)
+}
+
+// exactCountLimit is the total below which an estimated count is replaced by an exact one,
+// which is cheap for so few songs and spares clients the planner's guesswork.
+const exactCountLimit = 10000
+
+// countSongs returns the number of songs matching filter, counted or estimated as the filter asks,
+// and whether the number is an estimate.
+func (s *SongService) countSongs(ctx context.Context, filter *models.SongFilter) (int64, bool, error) {
+	if filter.Count == models.CountEstimated {
+		estimate, err := s.repository.EstimateSongs(ctx, filter)
+		if err != nil {
+			slog.Error("Failed to estimate songs", "error", err)
+			return 0, false, fmt.Errorf("error estimating songs: %w", err)
+		}
+		if estimate >= exactCountLimit {
+			return estimate, true, nil
+		}
+	}
+
+	count, err := s.repository.CountSongs(ctx, filter)
+	if err != nil {
+		slog.Error("Failed to count songs", "error", err)
+		return 0, false, fmt.Errorf("error counting songs: %w", err)
+	}
+	return count, false, nil
+}
+
+// GetSongPaginated returns a page of the songs matching filter, by offset, with their total.
+func (s *SongService) GetSongPaginated(ctx context.Context, filter *models.SongFilter, page, pageSize int) (*models.SongPage, error) {
 	slog.Info("Fetching filtered songs", "filter", filter, "page", page, "pageSize", pageSize)
 
-	songs, err := s.repository.GetSongPaginated(filter, page, pageSize)
+	filter, err := s.searchFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	songs, err := s.repository.GetSongPaginated(ctx, filter, page, pageSize)
 	if err != nil {
 		slog.Error("Failed to fetch filtered songs", "error", err)
 		return nil, fmt.Errorf("error fetching songs: %w", err)
 	}
 
-	slog.Info("Successfully fetched filtered songs", "count", len(songs))
-	return songs, nil
+	result := &models.SongPage{Songs: songs}
+	// A short page that is not past the end is the last one, which tells the total without counting.
+	if len(songs) < pageSize && (len(songs) > 0 || page == 1) {
+		result.Total = int64((page-1)*pageSize + len(songs))
+	} else if result.Total, result.Estimated, err = s.countSongs(ctx, filter); err != nil {
+		return nil, err
+	}
+
+	slog.Info("Successfully fetched filtered songs", "count", len(songs), "total", result.Total)
+	return result, nil
+}
+
+// GetSongsByCursor returns the page of songs at the cursor, or the first page without one,
+// with the cursors of the neighbouring pages and the total of the list.
+func (s *SongService) GetSongsByCursor(ctx context.Context, filter *models.SongFilter, cursor *models.SongCursor, pageSize int) (*models.SongPage, error) {
+	slog.Info("Fetching songs by cursor", "filter", filter, "cursor", cursor, "pageSize", pageSize)
+
+	if len(filter.Sort) > 0 {
+		return nil, apperrors.New(apperrors.ErrValidation, "cursors only page songs in list order, page a sorted list by offset")
+	}
+	filter, err := s.searchFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	// One extra song tells whether there is a page beyond this one.
+	songs, err := s.repository.GetSongsByCursor(ctx, filter, cursor, pageSize+1)
+	if err != nil {
+		slog.Error("Failed to fetch songs by cursor", "error", err)
+		return nil, fmt.Errorf("error fetching songs: %w", err)
+	}
+
+	more := len(songs) > pageSize
+	if more {
+		songs = songs[:pageSize]
+	}
+
+	page := &models.SongPage{Songs: songs}
+	// A first page with nothing beyond it holds the whole list.
+	if cursor == nil && !more {
+		page.Total = int64(len(songs))
+	} else if page.Total, page.Estimated, err = s.countSongs(ctx, filter); err != nil {
+		return nil, err
+	}
+	if len(songs) == 0 {
+		return page, nil
+	}
+
+	if cursor != nil && cursor.Backward {
+		slices.Reverse(songs)
+		page.Next = models.CursorAfter(songs[len(songs)-1])
+		if more {
+			page.Prev = models.CursorBefore(songs[0])
+		}
+	} else {
+		if more {
+			page.Next = models.CursorAfter(songs[len(songs)-1])
+		}
+		if cursor != nil {
+			page.Prev = models.CursorBefore(songs[0])
+		}
+	}
+
+	return page, nil
+}
+
+func (s *SongService) ExportSongs(ctx context.Context, filter *models.SongFilter, fn func(*models.Song) error) error {
+	slog.Info("Exporting songs", "filter", filter)
+
+	filter, err := s.searchFilter(filter)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repository.ExportSongs(ctx, filter, fn); err != nil {
+		slog.Error("Failed to export songs", "error", err)
+		return fmt.Errorf("error exporting songs: %w", err)
+	}
+
+	return nil
 }
 
-func (s *SongService) GetSongTextPaginated(id string, page, pageSize int) ([]string, error) {
+// suggestionLimit is the number of "did you mean" names returned per search term.
+const suggestionLimit = 3
+
+// FuzzySearch returns a page of the songs whose group or song name is similar to the search terms,
+// tolerating typos, best match first. A zero threshold uses the configured one. The first page also
+// suggests existing names close to each term.
+func (s *SongService) FuzzySearch(ctx context.Context, q, group, song string, threshold float64, page, pageSize int) (*models.FuzzyResult, error) {
+	if threshold == 0 {
+		threshold = s.search.SimilarityThreshold
+	}
+	query, err := models.NewFuzzyQuery(q, group, song, threshold)
+	if err != nil {
+		return nil, err
+	}
+	slog.Info("Fuzzy searching songs", "query", query, "page", page, "pageSize", pageSize)
+
+	songs, err := s.repository.FuzzySearchSongs(ctx, query, page, pageSize)
+	if err != nil {
+		slog.Error("Failed to fuzzy search songs", "error", err)
+		return nil, fmt.Errorf("error searching songs: %w", err)
+	}
+
+	result := &models.FuzzyResult{Songs: songs}
+	if page > 1 {
+		return result, nil
+	}
+
+	var suggestions models.NameSuggestions
+	for _, term := range []struct {
+		field, value string
+		target       *[]string
+	}{
+		{"q", query.Q, &suggestions.Q},
+		{"group", query.Group, &suggestions.Group},
+		{"song", query.Song, &suggestions.Song},
+	} {
+		if term.value == "" {
+			continue
+		}
+		names, err := s.repository.SuggestNames(ctx, term.field, term.value, suggestionLimit)
+		if err != nil {
+			slog.Error("Failed to fetch name suggestions", "field", term.field, "error", err)
+			return nil, fmt.Errorf("error fetching suggestions: %w", err)
+		}
+		*term.target = names
+	}
+	if len(suggestions.Q)+len(suggestions.Group)+len(suggestions.Song) > 0 {
+		result.DidYouMean = &suggestions
+	}
+
+	slog.Info("Successfully fuzzy searched songs", "count", len(songs))
+	return result, nil
+}
+
+// autocompleteTarget is the lookup time autocomplete is meant to stay under while typing.
+const autocompleteTarget = 50 * time.Millisecond
+
+// Autocomplete returns up to limit songs whose names best match the text typed so far.
+func (s *SongService) Autocomplete(ctx context.Context, term string, limit int) ([]models.SongSuggestion, error) {
+	term = strings.TrimSpace(term)
+	if len([]rune(term)) < 2 {
+		return nil, apperrors.New(apperrors.ErrValidation, "q must be at least 2 characters long")
+	}
+
+	start := time.Now()
+	suggestions, err := s.repository.Autocomplete(ctx, term, limit)
+	if err != nil {
+		slog.Error("Failed to autocomplete", "term", term, "error", err)
+		return nil, fmt.Errorf("error autocompleting: %w", err)
+	}
+	if elapsed := time.Since(start); elapsed > autocompleteTarget {
+		slog.Warn("Autocomplete exceeded its latency target", "term", term, "elapsed", elapsed, "target", autocompleteTarget)
+	}
+
+	return suggestions, nil
+}
+
+// GetSongTextPaginated returns a page of the song's lyrics sections and the number of sections it has.
+func (s *SongService) GetSongTextPaginated(ctx context.Context, id string, page, pageSize int) ([]models.LyricsSection, int, error) {
 	slog.Info("Fetching song lyrics with pagination", "id", id, "page", page, "pageSize", pageSize)
 
-	verses, err := s.repository.GetSongTextPaginated(id, page, pageSize)
+	verses, total, err := s.repository.GetSongTextPaginated(ctx, id, page, pageSize)
 	if err != nil {
 		slog.Error("Failed to fetch song lyrics", "id", id, "error", err)
-		return nil, fmt.Errorf("error fetching song lyrics: %w", err)
+		return nil, 0, fmt.Errorf("error fetching song lyrics: %w", err)
+	}
+
+	slog.Info("Successfully fetched song lyrics", "id", id, "verses_count", len(verses), "total", total)
+	return verses, total, nil
+}
+
+// GetSongSections returns the song's lyrics sections numbered from through to, inclusively, and the
+// number of sections it has. A zero to reads up to the last section.
+func (s *SongService) GetSongSections(ctx context.Context, id string, from, to int) ([]models.LyricsSection, int, error) {
+	if to == 0 {
+		to = math.MaxInt32
+	}
+	if from < 1 || to < from {
+		return nil, 0, apperrors.New(apperrors.ErrValidation, "from must be at least 1 and not greater than to")
+	}
+
+	sections, total, err := s.repository.GetSongSections(ctx, id, from, to)
+	if err != nil {
+		slog.Error("Failed to fetch lyrics sections", "id", id, "from", from, "to", to, "error", err)
+		return nil, 0, err
+	}
+
+	return sections, total, nil
+}
+
+// GetSongSection returns a single lyrics section of the song.
+func (s *SongService) GetSongSection(ctx context.Context, id string, number int) (*models.LyricsSection, error) {
+	sections, _, err := s.GetSongSections(ctx, id, number, number)
+	if err != nil {
+		return nil, err
+	}
+	if len(sections) == 0 {
+		return nil, apperrors.New(apperrors.ErrNotFound, "song %s has no section %d", id, number)
 	}
 
-	slog.Info("Successfully fetched song lyrics", "id", id, "verses_count", len(verses))
-	return verses, nil
+	return &sections[0], nil
 }
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_song_jobs_running;
+DROP INDEX IF EXISTS idx_song_jobs_pending;
+
+DROP TABLE IF EXISTS song_jobs;
@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS song_jobs (
+    id VARCHAR(255) PRIMARY KEY,
+    group_name VARCHAR(255) NOT NULL,
+    song_name VARCHAR(255) NOT NULL,
+    status VARCHAR(16) NOT NULL DEFAULT 'pending',
+    attempts INT NOT NULL DEFAULT 0,
+    last_error TEXT NOT NULL DEFAULT '',
+    song_id VARCHAR(255) NOT NULL DEFAULT '',
+    run_after TIMESTAMPTZ NOT NULL DEFAULT now(),
+    locked_until TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT song_jobs_status_check CHECK (status IN ('pending', 'running', 'succeeded', 'failed'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_song_jobs_pending ON song_jobs(run_after) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_song_jobs_running ON song_jobs(locked_until) WHERE status = 'running';
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_song_artists_one_primary;
+DROP INDEX IF EXISTS idx_song_artists_artist;
+
+DROP TABLE IF EXISTS song_artists;
+DROP TABLE IF EXISTS artists;
@@ -0,0 +1,39 @@
+CREATE TABLE IF NOT EXISTS artists (
+    id BIGSERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    normalized_name VARCHAR(255) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT unique_artist_name UNIQUE (normalized_name)
+);
+
+CREATE TABLE IF NOT EXISTS song_artists (
+    song_id VARCHAR(255) NOT NULL REFERENCES songs(id) ON DELETE CASCADE,
+    artist_id BIGINT NOT NULL REFERENCES artists(id) ON DELETE RESTRICT,
+    role VARCHAR(16) NOT NULL,
+    position INT NOT NULL DEFAULT 0,
+    PRIMARY KEY (song_id, artist_id, role),
+    CONSTRAINT song_artists_role_check CHECK (role IN ('primary', 'featured', 'composer', 'producer'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_song_artists_artist ON song_artists(artist_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_song_artists_one_primary ON song_artists(song_id) WHERE role = 'primary';
+
+-- Backfill one artist per distinct group name the way models.NormalizeArtistName merges them:
+-- runs of whitespace, including the Unicode spaces strings.Fields splits on, become one space,
+-- the name is trimmed and compared ignoring case. Songs with a blank group name get no artist.
+INSERT INTO artists (name, normalized_name)
+SELECT min(name), lower(name)
+FROM (SELECT btrim(regexp_replace(group_name, '[\s\u0085\u00a0\u1680\u2000-\u200a\u2028\u2029\u202f\u205f\u3000]+', ' ', 'g')) AS name
+      FROM songs) names
+WHERE name <> ''
+GROUP BY lower(name)
+ON CONFLICT (normalized_name) DO NOTHING;
+
+INSERT INTO song_artists (song_id, artist_id, role)
+SELECT s.id, a.id, 'primary'
+FROM (SELECT id, lower(btrim(regexp_replace(group_name, '[\s\u0085\u00a0\u1680\u2000-\u200a\u2028\u2029\u202f\u205f\u3000]+', ' ', 'g'))) AS normalized_name
+      FROM songs) s
+JOIN artists a ON a.normalized_name = s.normalized_name
+WHERE s.normalized_name <> ''
+ON CONFLICT DO NOTHING;
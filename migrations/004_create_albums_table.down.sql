@@ -0,0 +1,19 @@
+-- Songs could not lack a release date before albums, and no date can be made up for those that
+-- do now, so the migration refuses to run until every song has one.
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM songs WHERE release_date IS NULL) THEN
+        RAISE EXCEPTION 'songs without a release date cannot be migrated down, set their release_date first';
+    END IF;
+END $$;
+
+ALTER TABLE songs
+    DROP CONSTRAINT IF EXISTS unique_album_track,
+    DROP CONSTRAINT IF EXISTS songs_track_check,
+    DROP COLUMN IF EXISTS track_number,
+    DROP COLUMN IF EXISTS disc_number,
+    DROP COLUMN IF EXISTS album_id;
+
+ALTER TABLE songs ALTER COLUMN release_date SET NOT NULL;
+
+DROP TABLE IF EXISTS albums;
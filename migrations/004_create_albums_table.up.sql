@@ -0,0 +1,23 @@
+CREATE TABLE IF NOT EXISTS albums (
+    id BIGSERIAL PRIMARY KEY,
+    title VARCHAR(255) NOT NULL,
+    artist_id BIGINT NOT NULL REFERENCES artists(id) ON DELETE RESTRICT,
+    release_date DATE,
+    cover_link VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT unique_album UNIQUE (artist_id, title)
+);
+
+-- Songs without a release date from the provider inherit the date of their album.
+ALTER TABLE songs ALTER COLUMN release_date DROP NOT NULL;
+
+ALTER TABLE songs
+    ADD COLUMN IF NOT EXISTS album_id BIGINT REFERENCES albums(id),
+    ADD COLUMN IF NOT EXISTS disc_number INT,
+    ADD COLUMN IF NOT EXISTS track_number INT,
+    ADD CONSTRAINT songs_track_check CHECK (
+        (album_id IS NULL AND disc_number IS NULL AND track_number IS NULL)
+        OR (album_id IS NOT NULL AND disc_number >= 1 AND track_number >= 1)
+    ),
+    ADD CONSTRAINT unique_album_track UNIQUE (album_id, disc_number, track_number);
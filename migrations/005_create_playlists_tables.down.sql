@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_playlist_entries_song;
+
+DROP TABLE IF EXISTS playlist_entries;
+DROP TABLE IF EXISTS playlists;
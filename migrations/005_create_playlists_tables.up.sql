@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS playlists (
+    id BIGSERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    version INT NOT NULL DEFAULT 1,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- Entries of a deleted song are removed with it; the remaining entries keep their order.
+CREATE TABLE IF NOT EXISTS playlist_entries (
+    playlist_id BIGINT NOT NULL REFERENCES playlists(id) ON DELETE CASCADE,
+    song_id VARCHAR(255) NOT NULL REFERENCES songs(id) ON DELETE CASCADE,
+    position INT NOT NULL,
+    added_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (playlist_id, song_id),
+    -- Deferred so a reorder can rewrite every position in one transaction.
+    CONSTRAINT unique_playlist_position UNIQUE (playlist_id, position) DEFERRABLE INITIALLY DEFERRED
+);
+
+CREATE INDEX IF NOT EXISTS idx_playlist_entries_song ON playlist_entries(song_id);
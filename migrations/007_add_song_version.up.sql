@@ -0,0 +1,2 @@
+-- Every update bumps the version, which is exposed as the song's ETag.
+ALTER TABLE songs ADD COLUMN IF NOT EXISTS version INT NOT NULL DEFAULT 1;
@@ -0,0 +1,31 @@
+-- Revisions outlive their song, so song_id is not a foreign key.
+CREATE TABLE IF NOT EXISTS song_revisions (
+    id BIGSERIAL PRIMARY KEY,
+    song_id VARCHAR(255) NOT NULL,
+    revision INT NOT NULL,
+    action VARCHAR(16) NOT NULL,
+    actor VARCHAR(255) NOT NULL,
+    source_revision INT,
+    before JSONB,
+    after JSONB,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT unique_song_revision UNIQUE (song_id, revision),
+    CONSTRAINT song_revisions_action_check CHECK (action IN ('insert', 'update', 'delete', 'revert'))
+);
+
+-- Songs added before the audit log start with an insert revision of their current state.
+INSERT INTO song_revisions (song_id, revision, action, actor, after)
+SELECT id, 1, 'insert', 'system', json_build_object(
+           'id', id,
+           'group_name', group_name,
+           'song_name', song_name,
+           'release_date', COALESCE(to_char(release_date, 'YYYY-MM-DD"T00:00:00Z"'), ''),
+           'text', text,
+           'link', link,
+           'album_id', album_id,
+           'disc_number', disc_number,
+           'track_number', track_number,
+           'version', version
+       )::jsonb
+FROM songs
+ON CONFLICT DO NOTHING;
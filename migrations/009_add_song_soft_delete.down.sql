@@ -0,0 +1,16 @@
+DELETE FROM song_revisions WHERE action IN ('restore', 'purge');
+ALTER TABLE song_revisions DROP CONSTRAINT IF EXISTS song_revisions_action_check;
+ALTER TABLE song_revisions ADD CONSTRAINT song_revisions_action_check
+    CHECK (action IN ('insert', 'update', 'delete', 'revert'));
+
+-- Songs still in the trash are removed for good, since the unique constraints cannot hold them.
+DELETE FROM songs WHERE deleted_at IS NOT NULL;
+
+DROP INDEX IF EXISTS unique_album_track;
+ALTER TABLE songs ADD CONSTRAINT unique_album_track UNIQUE (album_id, disc_number, track_number);
+
+DROP INDEX IF EXISTS unique_song;
+ALTER TABLE songs ADD CONSTRAINT unique_song UNIQUE (group_name, song_name);
+
+DROP INDEX IF EXISTS idx_songs_deleted_at;
+ALTER TABLE songs DROP COLUMN IF EXISTS deleted_at;
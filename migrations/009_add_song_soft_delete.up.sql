@@ -0,0 +1,15 @@
+-- Deleted songs stay in the table until the purger removes them after the retention period.
+ALTER TABLE songs ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_songs_deleted_at ON songs(deleted_at) WHERE deleted_at IS NOT NULL;
+
+-- A deleted song does not block adding the same song again or reusing its album track.
+ALTER TABLE songs DROP CONSTRAINT IF EXISTS unique_song;
+CREATE UNIQUE INDEX IF NOT EXISTS unique_song ON songs(group_name, song_name) WHERE deleted_at IS NULL;
+
+ALTER TABLE songs DROP CONSTRAINT IF EXISTS unique_album_track;
+CREATE UNIQUE INDEX IF NOT EXISTS unique_album_track ON songs(album_id, disc_number, track_number) WHERE deleted_at IS NULL;
+
+ALTER TABLE song_revisions DROP CONSTRAINT IF EXISTS song_revisions_action_check;
+ALTER TABLE song_revisions ADD CONSTRAINT song_revisions_action_check
+    CHECK (action IN ('insert', 'update', 'delete', 'revert', 'restore', 'purge'));
@@ -0,0 +1,3 @@
+-- Supports the song list order, newest first with undated songs last and the ID as tiebreaker.
+CREATE INDEX IF NOT EXISTS idx_songs_release_id ON songs ((COALESCE(release_date, '-infinity'::date)) DESC, id DESC)
+    WHERE deleted_at IS NULL;
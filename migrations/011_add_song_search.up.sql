@@ -0,0 +1,12 @@
+-- The text search configuration of each song. Existing songs were searched in Russian.
+ALTER TABLE songs ADD COLUMN IF NOT EXISTS language regconfig NOT NULL DEFAULT 'russian';
+ALTER TABLE songs ALTER COLUMN language SET DEFAULT 'simple';
+
+-- Title, group and lyrics are weighted A, B and C for ranking.
+ALTER TABLE songs ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector(language, song_name), 'A') ||
+    setweight(to_tsvector(language, group_name), 'B') ||
+    setweight(to_tsvector(language, text), 'C')
+) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_songs_search ON songs USING GIN (search_vector);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_songs_title_trgm;
+DROP INDEX IF EXISTS idx_songs_name_trgm;
+DROP INDEX IF EXISTS idx_songs_group_trgm;
+-- The extension is left installed, as other objects may depend on it.
@@ -0,0 +1,10 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- GiST rather than GIN, so that besides the similarity operators the indexes serve
+-- nearest-neighbour ordering by trigram distance for suggestions and autocomplete.
+CREATE INDEX IF NOT EXISTS idx_songs_group_trgm ON songs USING GIST (group_name gist_trgm_ops)
+    WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_songs_name_trgm ON songs USING GIST (song_name gist_trgm_ops)
+    WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_songs_title_trgm ON songs USING GIST ((group_name || ' ' || song_name) gist_trgm_ops)
+    WHERE deleted_at IS NULL;
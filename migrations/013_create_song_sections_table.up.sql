@@ -0,0 +1,10 @@
+-- Lyrics split into ordered sections. songs.text stays the source they are parsed from; existing
+-- songs are parsed by the application at startup.
+CREATE TABLE IF NOT EXISTS song_sections (
+    song_id VARCHAR(255) NOT NULL REFERENCES songs(id) ON DELETE CASCADE,
+    position INT NOT NULL,
+    kind VARCHAR(16) NOT NULL,
+    lines TEXT[] NOT NULL,
+    PRIMARY KEY (song_id, position),
+    CONSTRAINT song_sections_kind_check CHECK (kind IN ('verse', 'chorus', 'bridge'))
+);
@@ -0,0 +1,2 @@
+ALTER TABLE song_jobs DROP COLUMN IF EXISTS actor_role;
+ALTER TABLE song_jobs DROP COLUMN IF EXISTS actor;
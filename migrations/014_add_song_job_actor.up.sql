@@ -0,0 +1,2 @@
+ALTER TABLE song_jobs ADD COLUMN IF NOT EXISTS actor VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE song_jobs ADD COLUMN IF NOT EXISTS actor_role VARCHAR(16) NOT NULL DEFAULT '';
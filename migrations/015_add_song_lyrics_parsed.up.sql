@@ -0,0 +1,9 @@
+-- Tracks the songs whose lyrics still have to be split into sections, so that the startup
+-- backfill reads a small partial index instead of scanning every song. New songs are parsed
+-- when they are stored; the songs left from before song_sections are flagged once here.
+ALTER TABLE songs ADD COLUMN IF NOT EXISTS lyrics_parsed BOOLEAN NOT NULL DEFAULT true;
+
+UPDATE songs SET lyrics_parsed = false
+WHERE text ~ '\S' AND NOT EXISTS (SELECT 1 FROM song_sections WHERE song_id = songs.id);
+
+CREATE INDEX IF NOT EXISTS idx_songs_lyrics_unparsed ON songs(id) WHERE NOT lyrics_parsed;